@@ -29,7 +29,7 @@ import (
 )
 
 func usage() {
-	fmt.Fprintf(os.Stderr, "usage: %s [collector | volunteer] ARGS\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "usage: %s [collector | volunteer | schema] ARGS\n", os.Args[0])
 	os.Exit(1)
 }
 
@@ -56,6 +56,8 @@ func main() {
 		prog = collectorSubProgram{}
 	case "volunteer":
 		prog = volunteerSubProgram{}
+	case "schema":
+		prog = schemaSubProgram{}
 	default:
 		usage()
 	}
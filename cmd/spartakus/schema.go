@@ -0,0 +1,61 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/database"
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+	"github.com/spf13/pflag"
+	"github.com/thockin/logr"
+)
+
+var schemaConfig = struct {
+	format string
+}{}
+
+type schemaSubProgram struct{}
+
+func (_ schemaSubProgram) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&schemaConfig.format, "format", "json", "Which schema to print: 'json' for a JSON Schema of reports, 'bigquery' for the BigQuery table schema")
+}
+
+func (_ schemaSubProgram) Validate() error {
+	if schemaConfig.format != "json" && schemaConfig.format != "bigquery" {
+		return fmt.Errorf("invalid value for --format: must be 'json' or 'bigquery'")
+	}
+	return nil
+}
+
+func (_ schemaSubProgram) Main(log logr.Logger) error {
+	var schema interface{}
+	switch schemaConfig.format {
+	case "json":
+		schema = report.GenerateJSONSchema()
+	case "bigquery":
+		schema = database.BigquerySchema()
+	}
+
+	j, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode schema: %v", err)
+	}
+	fmt.Println(string(j))
+	return nil
+}
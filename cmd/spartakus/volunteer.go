@@ -28,11 +28,14 @@ import (
 )
 
 var volunteerConfig = struct {
-	clusterID      string
-	period         time.Duration
-	database       string
-	printDatabases bool
-	extensionsPath string
+	clusterID              string
+	period                 time.Duration
+	database               string
+	printDatabases         bool
+	extensions             volunteer.ExtensionsConfig
+	groupNodes             bool
+	distributionRulesPath  string
+	cloudProviderRulesPath string
 }{}
 
 type volunteerSubProgram struct{}
@@ -43,13 +46,35 @@ func (_ volunteerSubProgram) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&volunteerConfig.database, "database",
 		"https://spartakus.k8s.io", "Send reports to this database; use --print-databases for a list of options")
 	fs.BoolVar(&volunteerConfig.printDatabases, "print-databases", false, "Print database options and exit")
-	fs.StringVar(&volunteerConfig.extensionsPath, "extensions", "", "Path to a file of additional metrics to report; leave unset to report no additional metrics")
+	fs.StringVar(&volunteerConfig.extensions.Path, "extensions", "", "Path to a file of additional metrics to report; leave unset to report no additional metrics")
+	fs.StringVar(&volunteerConfig.extensions.ConfigMapSelector, "extensions-configmap-selector", "", "Label selector for ConfigMaps, in any namespace, whose data are additional metrics to report; leave unset to read no ConfigMaps")
+	fs.StringVar(&volunteerConfig.extensions.AnnotationPrefix, "extensions-annotation-prefix", "", "Prefix of the annotations of the kube-system namespace that are additional metrics to report, e.g. 'example.com/'; leave unset to read no annotations")
+	fs.StringSliceVar(&volunteerConfig.extensions.MetricsEndpoints, "extensions-metrics-endpoints", nil, "Comma-separated URLs of OpenMetrics or Prometheus endpoints to scrape for additional metrics to report; leave unset to scrape none")
+	fs.StringSliceVar(&volunteerConfig.extensions.MetricsAllowlist, "extensions-metrics-allowlist", nil, "Comma-separated names of the gauges and counters to report from --extensions-metrics-endpoints")
+	fs.DurationVar(&volunteerConfig.extensions.MetricsTimeout, "extensions-metrics-timeout", 10*time.Second, "How long scraping each extensions metrics endpoint may take")
+	fs.BoolVar(&volunteerConfig.extensions.MetricsInClusterAuth, "extensions-metrics-in-cluster-auth", false, "Send the token of the pod's service account to https --extensions-metrics-endpoints, and trust the cluster's CA, e.g. to scrape the apiserver")
+	fs.StringVar(&volunteerConfig.extensions.HooksDir, "extensions-hooks", "", "Path to a directory of executables to run for every report, whose output are additional metrics to report; leave unset to run no hooks")
+	fs.DurationVar(&volunteerConfig.extensions.HookTimeout, "extensions-hook-timeout", 10*time.Second, "How long each extensions hook may run before it is killed")
+	fs.BoolVar(&volunteerConfig.extensions.Strict, "extensions-strict", false, "Fail the report if any extension is invalid, instead of leaving out the invalid ones")
+	fs.BoolVar(&volunteerConfig.extensions.Diagnostics, "extensions-diagnostics", false, "Include in reports which extension files were read and the problems found in them")
+	fs.BoolVar(&volunteerConfig.groupNodes, "group-nodes", false, "Report groups of nodes with identical attributes instead of individual nodes, for smaller reports from large clusters")
+	fs.StringVar(&volunteerConfig.distributionRulesPath, "distribution-rules", "", "Path to a JSON file of rules for detecting the kubernetes distribution; leave unset to use the built-in rules")
+	fs.StringVar(&volunteerConfig.cloudProviderRulesPath, "cloud-provider-rules", "", "Path to a JSON file of rules for detecting the cloud provider of nodes; leave unset to use the built-in rules")
 }
 
 func (_ volunteerSubProgram) Validate() error {
 	if volunteerConfig.clusterID == "" {
 		return fmt.Errorf("invalid value for --cluster-id: must not be empty")
 	}
+	if len(volunteerConfig.extensions.MetricsEndpoints) > 0 && len(volunteerConfig.extensions.MetricsAllowlist) == 0 {
+		return fmt.Errorf("invalid value for --extensions-metrics-allowlist: must not be empty when --extensions-metrics-endpoints is set")
+	}
+	if volunteerConfig.extensions.MetricsTimeout <= 0 {
+		return fmt.Errorf("invalid value for --extensions-metrics-timeout: must be positive")
+	}
+	if volunteerConfig.extensions.HookTimeout <= 0 {
+		return fmt.Errorf("invalid value for --extensions-hook-timeout: must be positive")
+	}
 	return nil
 }
 
@@ -67,7 +92,7 @@ func (_ volunteerSubProgram) Main(log logr.Logger) error {
 		return fmt.Errorf("failed to initialize database: %v", err)
 	}
 
-	volunteer, err := volunteer.New(log, volunteerConfig.clusterID, volunteerConfig.period, db, volunteerConfig.extensionsPath)
+	volunteer, err := volunteer.New(log, volunteerConfig.clusterID, volunteerConfig.period, db, volunteerConfig.extensions, volunteerConfig.groupNodes, volunteerConfig.distributionRulesPath, volunteerConfig.cloudProviderRulesPath)
 	if err != nil {
 		return fmt.Errorf("failed initializing volunteer: %v", err)
 	}
@@ -22,20 +22,27 @@ import (
 	"io/ioutil"
 	"net"
 	"net/http"
+	"sort"
 	"strconv"
+	"time"
 
+	"github.com/ghodss/yaml"
+	"github.com/gogo/protobuf/proto"
 	"github.com/gorilla/handlers"
 	"github.com/julienschmidt/httprouter"
 	"github.com/kubernetes-incubator/spartakus/pkg/database"
 	"github.com/kubernetes-incubator/spartakus/pkg/report"
+	reportv2 "github.com/kubernetes-incubator/spartakus/pkg/report/v2"
 	"github.com/kubernetes-incubator/spartakus/pkg/version"
 	"github.com/thockin/logr"
 )
 
 var (
-	CollectorEndpoint = "/api/v1"
-	HealthEndpoint    = "/healthz"
-	VersionEndpoint   = "/version"
+	CollectorEndpoint   = "/api/v1"
+	CollectorV2Endpoint = "/api/v2"
+	SchemaEndpoint      = "/api/v1/schema"
+	HealthEndpoint      = "/healthz"
+	VersionEndpoint     = "/version"
 )
 
 type APIServer struct {
@@ -69,13 +76,72 @@ func (lw logWriter) Write(b []byte) (int, error) {
 func (s *APIServer) newHandler() http.Handler {
 	m := httprouter.New()
 	m.Handle("GET", "/", s.healthHandler())
-	m.Handle("POST", CollectorEndpoint, s.storeRecordHandler())
+	m.Handle("POST", CollectorEndpoint, s.storeRecordHandler(v1Decoders))
+	m.Handle("POST", CollectorV2Endpoint, s.storeRecordHandler(v2Decoders))
+	m.Handle("GET", SchemaEndpoint, s.schemaHandler())
 	m.Handle("GET", HealthEndpoint, s.healthHandler())
 	m.Handle("GET", VersionEndpoint, s.versionHandler())
 	return m
 }
 
-func (s *APIServer) storeRecordHandler() httprouter.Handle {
+// recordDecoder decodes a request body into the canonical report.Record form
+// that is handed to the database.
+type recordDecoder func(body []byte) (report.Record, error)
+
+const (
+	contentTypeJSON     = "application/json"
+	contentTypeYAML     = "application/yaml"
+	contentTypeProtobuf = "application/x-protobuf"
+)
+
+// v1Decoders are the content types accepted at CollectorEndpoint.
+var v1Decoders = map[string]recordDecoder{
+	contentTypeJSON:     decodeV1JSON,
+	contentTypeYAML:     decodeV1YAML,
+	contentTypeProtobuf: decodeV1Protobuf,
+}
+
+// v2Decoders are the content types accepted at CollectorV2Endpoint.
+var v2Decoders = map[string]recordDecoder{
+	contentTypeJSON: decodeV2JSON,
+	contentTypeYAML: decodeV2YAML,
+}
+
+func decodeV1JSON(body []byte) (report.Record, error) {
+	var rec report.Record
+	err := json.Unmarshal(body, &rec)
+	return rec, err
+}
+
+func decodeV1YAML(body []byte) (report.Record, error) {
+	var rec report.Record
+	err := yaml.Unmarshal(body, &rec)
+	return rec, err
+}
+
+func decodeV1Protobuf(body []byte) (report.Record, error) {
+	var rec report.Record
+	err := proto.Unmarshal(body, &rec)
+	return rec, err
+}
+
+func decodeV2JSON(body []byte) (report.Record, error) {
+	var rec reportv2.Record
+	if err := json.Unmarshal(body, &rec); err != nil {
+		return report.Record{}, err
+	}
+	return reportv2.ConvertToV1(rec), nil
+}
+
+func decodeV2YAML(body []byte) (report.Record, error) {
+	var rec reportv2.Record
+	if err := yaml.Unmarshal(body, &rec); err != nil {
+		return report.Record{}, err
+	}
+	return reportv2.ConvertToV1(rec), nil
+}
+
+func (s *APIServer) storeRecordHandler(decoders map[string]recordDecoder) httprouter.Handle {
 	handle := func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 		body, err := ioutil.ReadAll(r.Body)
 		if err != nil {
@@ -83,13 +149,22 @@ func (s *APIServer) storeRecordHandler() httprouter.Handle {
 			return
 		}
 
-		var rec report.Record
-		if err := json.Unmarshal(body, &rec); err != nil {
+		// contentTypeMiddleware only lets through types we can decode.
+		decode := decoders[mediaType(r.Header)]
+		rec, err := decode(body)
+		if err != nil {
 			writeError(w, http.StatusBadRequest, fmt.Errorf("failed to decode record: %v", err))
 			return
 		}
+		// The timestamp is provided by the server, client values are ignored.
+		rec.Timestamp = strconv.FormatInt(time.Now().Unix(), 10)
 		s.logRecord(&rec)
 
+		if errs := rec.Validate(); len(errs) > 0 {
+			writeFieldErrors(w, http.StatusUnprocessableEntity, errs)
+			return
+		}
+
 		if err := s.Database.Store(rec); err != nil {
 			writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to store record: %v", err))
 			return
@@ -97,7 +172,13 @@ func (s *APIServer) storeRecordHandler() httprouter.Handle {
 
 		w.WriteHeader(http.StatusNoContent)
 	}
-	return contentTypeMiddleware(handle, "application/json")
+
+	contentTypes := []string{}
+	for ct := range decoders {
+		contentTypes = append(contentTypes, ct)
+	}
+	sort.Strings(contentTypes)
+	return contentTypeMiddleware(handle, contentTypes...)
 }
 
 func (s *APIServer) logRecord(r *report.Record) {
@@ -117,6 +198,21 @@ func (s *APIServer) healthHandler() httprouter.Handle {
 	}
 }
 
+func (s *APIServer) schemaHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+		j, err := json.MarshalIndent(report.GenerateJSONSchema(), "", "  ")
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to encode schema: %v", err))
+			return
+		}
+		w.Header().Set("Content-Type", "application/schema+json")
+		w.WriteHeader(http.StatusOK)
+		if _, err := w.Write(j); err != nil {
+			s.Log.Errorf("failed writing schema response: %v", err)
+		}
+	}
+}
+
 func (s *APIServer) versionHandler() httprouter.Handle {
 	return func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
 		w.WriteHeader(http.StatusOK)
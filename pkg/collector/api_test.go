@@ -17,22 +17,32 @@ limitations under the License.
 package collector
 
 import (
+	"encoding/json"
 	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/gogo/protobuf/proto"
 	"github.com/kubernetes-incubator/spartakus/pkg/database"
 	"github.com/kubernetes-incubator/spartakus/pkg/report"
+	"github.com/kylelemons/godebug/pretty"
 	logrtest "github.com/thockin/logr/testing"
 )
 
 var (
 	httpHeaderJSONContentType = http.Header{"Content-Type": []string{"application/json"}}
-	minimumRecordJSON         = `{}`
+	minimumRecordJSON         = `{"version": "v1.0.0", "clusterID": "cluster"}`
+	v2RecordJSON              = `{
+		"version": "v1.0.0",
+		"clusterID": "cluster",
+		"masterVersion": "v1.4.6",
+		"nodes": [{"id": "node1", "kubeletVersion": "v1.4.6", "capacity": [{"resource": "memory", "quantity": "1Gi"}]}]
+	}`
 )
 
 // HandlerRoundTripper implements the net/http.RoundTripper using
@@ -176,6 +186,65 @@ func TestRecordResourceStoreBadRecord(t *testing.T) {
 	}
 }
 
+func TestRecordResourceStoreInvalidRecord(t *testing.T) {
+	tests := []struct {
+		body   string
+		fields []string
+	}{
+		{
+			body:   `{}`,
+			fields: []string{"version", "clusterID"},
+		},
+		{
+			body:   `{"version": "v1.0.0", "clusterID": "cluster", "nodes": [{"id": "n1"}, {"id": ""}]}`,
+			fields: []string{"nodes[1].id"},
+		},
+		{
+			body:   `{"version": "v1.0.0", "clusterID": "cluster", "extensions": [{"name": "bad_prefix/foo", "value": "bar"}]}`,
+			fields: []string{"extensions[0].name"},
+		},
+	}
+	for i, tt := range tests {
+		db := &memDatabase{}
+		srv := &testServer{Database: db}
+		cli := srv.HTTPClient(t)
+
+		req, err := http.NewRequest("POST", srv.URL(CollectorEndpoint), strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatalf("case %d: unable to create HTTP request: %v", i, err)
+		}
+
+		req.Header = httpHeaderJSONContentType
+
+		resp, err := cli.Do(req)
+		if err != nil {
+			t.Fatalf("case %d: unable to get HTTP response: %v", i, err)
+		}
+
+		wantStatusCode := http.StatusUnprocessableEntity
+		if wantStatusCode != resp.StatusCode {
+			t.Fatalf("case %d: incorrect status code: want=%d got=%d", i, wantStatusCode, resp.StatusCode)
+		}
+
+		var body fieldErrorsResponse
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("case %d: unable to decode response body: %v", i, err)
+		}
+		if len(body.Errors) != len(tt.fields) {
+			t.Fatalf("case %d: incorrect number of errors: want=%d got=%d", i, len(tt.fields), len(body.Errors))
+		}
+		for j := range tt.fields {
+			if body.Errors[j].Field != tt.fields[j] {
+				t.Errorf("case %d: incorrect field for error %d: want=%q got=%q", i, j, tt.fields[j], body.Errors[j].Field)
+			}
+		}
+
+		if len(db.Records) != 0 {
+			t.Errorf("case %d: invalid record was stored", i)
+		}
+	}
+}
+
 func TestRecordResoureStoreSuccess(t *testing.T) {
 	db := &memDatabase{}
 	srv := &testServer{Database: db}
@@ -197,4 +266,208 @@ func TestRecordResoureStoreSuccess(t *testing.T) {
 	if wantStatusCode != resp.StatusCode {
 		t.Fatalf("incorrect status code: want=%d got=%d", wantStatusCode, resp.StatusCode)
 	}
+
+	if len(db.Records) != 1 {
+		t.Fatalf("incorrect number of stored records: want=1 got=%d", len(db.Records))
+	}
+	if db.Records[0].Timestamp == "" {
+		t.Errorf("expected server-provided timestamp")
+	}
+}
+
+func TestRecordResourceStoreV2(t *testing.T) {
+	tests := []struct {
+		body           string
+		wantStatusCode int
+	}{
+		{v2RecordJSON, http.StatusNoContent},
+		{minimumRecordJSON, http.StatusNoContent},
+		{`{"version": "v1.0.0", "clusterID": "cluster", "masterVersion": "1.4"}`, http.StatusBadRequest},
+		{`{"version": "v1.0.0", "clusterID": ""}`, http.StatusUnprocessableEntity},
+	}
+	for i, tt := range tests {
+		db := &memDatabase{}
+		srv := &testServer{Database: db}
+		cli := srv.HTTPClient(t)
+
+		req, err := http.NewRequest("POST", srv.URL(CollectorV2Endpoint), strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatalf("case %d: unable to create HTTP request: %v", i, err)
+		}
+
+		req.Header = httpHeaderJSONContentType
+
+		resp, err := cli.Do(req)
+		if err != nil {
+			t.Fatalf("case %d: unable to get HTTP response: %v", i, err)
+		}
+
+		if tt.wantStatusCode != resp.StatusCode {
+			t.Fatalf("case %d: incorrect status code: want=%d got=%d", i, tt.wantStatusCode, resp.StatusCode)
+		}
+	}
+}
+
+func TestRecordResourceStoreV2Canonical(t *testing.T) {
+	db := &memDatabase{}
+	srv := &testServer{Database: db}
+	cli := srv.HTTPClient(t)
+
+	req, err := http.NewRequest("POST", srv.URL(CollectorV2Endpoint), strings.NewReader(v2RecordJSON))
+	if err != nil {
+		t.Fatalf("unable to create HTTP request: %v", err)
+	}
+
+	req.Header = httpHeaderJSONContentType
+
+	if _, err := cli.Do(req); err != nil {
+		t.Fatalf("unable to get HTTP response: %v", err)
+	}
+
+	if len(db.Records) != 1 {
+		t.Fatalf("incorrect number of stored records: want=1 got=%d", len(db.Records))
+	}
+	rec := db.Records[0]
+	if rec.MasterVersion == nil || *rec.MasterVersion != "v1.4.6" {
+		t.Errorf("incorrect master version: want=%q got=%v", "v1.4.6", rec.MasterVersion)
+	}
+	if len(rec.Nodes) != 1 || len(rec.Nodes[0].Capacity) != 1 || rec.Nodes[0].Capacity[0].Value != "1Gi" {
+		t.Errorf("incorrect nodes: %+v", rec.Nodes)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	db := &memDatabase{}
+	srv := &testServer{Database: db}
+	cli := srv.HTTPClient(t)
+
+	resp, err := cli.Get(srv.URL(SchemaEndpoint))
+	if err != nil {
+		t.Fatalf("unable to get HTTP response: %v", err)
+	}
+
+	wantStatusCode := http.StatusOK
+	if wantStatusCode != resp.StatusCode {
+		t.Fatalf("incorrect status code: want=%d got=%d", wantStatusCode, resp.StatusCode)
+	}
+
+	var schema report.JSONSchema
+	if err := json.NewDecoder(resp.Body).Decode(&schema); err != nil {
+		t.Fatalf("unable to decode response body: %v", err)
+	}
+	for _, name := range []string{"version", "clusterID", "nodes", "extensions"} {
+		if schema.Properties[name] == nil {
+			t.Errorf("schema is missing property %q", name)
+		}
+	}
+	for _, name := range []string{"Node", "Resource", "Extension"} {
+		if schema.Definitions[name] == nil {
+			t.Errorf("schema is missing definition %q", name)
+		}
+	}
+}
+
+func TestRecordResourceStoreContentTypes(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+	want := report.Record{
+		Version:       "v1.0.0",
+		ClusterID:     "cluster",
+		MasterVersion: strPtr("v1.4.6"),
+		Nodes: []report.Node{
+			{ID: "node1", Capacity: []report.Resource{{Resource: "cpu", Value: "4"}}},
+		},
+	}
+	protoBody, err := proto.Marshal(&want)
+	if err != nil {
+		t.Fatalf("unable to encode protobuf: %v", err)
+	}
+
+	tests := []struct {
+		endpoint       string
+		contentType    string
+		body           string
+		wantStatusCode int
+	}{
+		{
+			endpoint:       CollectorEndpoint,
+			contentType:    "application/x-protobuf",
+			body:           string(protoBody),
+			wantStatusCode: http.StatusNoContent,
+		},
+		{
+			endpoint:    CollectorEndpoint,
+			contentType: "application/yaml",
+			body: `
+version: v1.0.0
+clusterID: cluster
+masterVersion: v1.4.6
+nodes:
+- id: node1
+  capacity:
+  - resource: cpu
+    value: "4"
+`,
+			wantStatusCode: http.StatusNoContent,
+		},
+		{
+			endpoint:    CollectorV2Endpoint,
+			contentType: "application/yaml; charset=utf-8",
+			body: `
+version: v1.0.0
+clusterID: cluster
+masterVersion: v1.4.6
+nodes:
+- id: node1
+  capacity:
+  - resource: cpu
+    quantity: "4"
+`,
+			wantStatusCode: http.StatusNoContent,
+		},
+		{
+			endpoint:       CollectorEndpoint,
+			contentType:    "application/x-protobuf",
+			body:           "garbage",
+			wantStatusCode: http.StatusBadRequest,
+		},
+		{
+			endpoint:       CollectorV2Endpoint,
+			contentType:    "application/x-protobuf",
+			body:           string(protoBody),
+			wantStatusCode: http.StatusUnsupportedMediaType,
+		},
+	}
+	for i, tt := range tests {
+		db := &memDatabase{}
+		srv := &testServer{Database: db}
+		cli := srv.HTTPClient(t)
+
+		req, err := http.NewRequest("POST", srv.URL(tt.endpoint), strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatalf("case %d: unable to create HTTP request: %v", i, err)
+		}
+
+		req.Header = http.Header{"Content-Type": []string{tt.contentType}}
+
+		resp, err := cli.Do(req)
+		if err != nil {
+			t.Fatalf("case %d: unable to get HTTP response: %v", i, err)
+		}
+
+		if tt.wantStatusCode != resp.StatusCode {
+			t.Fatalf("case %d: incorrect status code: want=%d got=%d", i, tt.wantStatusCode, resp.StatusCode)
+		}
+		if resp.StatusCode != http.StatusNoContent {
+			continue
+		}
+
+		if len(db.Records) != 1 {
+			t.Fatalf("case %d: incorrect number of stored records: want=1 got=%d", i, len(db.Records))
+		}
+		got := db.Records[0]
+		got.Timestamp = ""
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("case %d: did not store expected record:\n%s", i, pretty.Compare(got, want))
+		}
+	}
 }
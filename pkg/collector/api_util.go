@@ -17,11 +17,13 @@ limitations under the License.
 package collector
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/julienschmidt/httprouter"
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
 )
 
 // contentTypeMiddleware wraps and returns a httprouter.Handle, validating the request
@@ -50,11 +52,17 @@ func contentTypeMiddleware(handle httprouter.Handle, contentTypes ...string) htt
 // isContentType validates the Content-Type header
 // is contentType. That is, its type and subtype match.
 func isContentType(h http.Header, contentType string) bool {
+	return mediaType(h) == contentType
+}
+
+// mediaType returns the type and subtype of the Content-Type header,
+// without any parameters.
+func mediaType(h http.Header) string {
 	ct := h.Get("Content-Type")
 	if i := strings.IndexRune(ct, ';'); i != -1 {
 		ct = ct[0:i]
 	}
-	return ct == contentType
+	return ct
 }
 
 // writeError writes an error value.
@@ -67,3 +75,25 @@ func writeError(w http.ResponseWriter, code int, err error) error {
 	}
 	return nil
 }
+
+// fieldErrorsResponse is the body written by writeFieldErrors.
+type fieldErrorsResponse struct {
+	Errors []report.FieldError `json:"errors"`
+}
+
+// writeFieldErrors writes a JSON list of invalid fields.
+func writeFieldErrors(w http.ResponseWriter, code int, errs []report.FieldError) error {
+	body, err := json.Marshal(fieldErrorsResponse{Errors: errs})
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to encode errors: %v", err))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	_, err = w.Write(body)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -124,6 +124,41 @@ func (bqdb bqDatabase) Store(rec report.Record) error {
 	return nil
 }
 
+// BigquerySchema returns the schema of the table that report.Records are
+// stored in.  It is generated from the report types, see report.Fields.
+func BigquerySchema() []*bigquery.TableFieldSchema {
+	return bigqueryFields(report.Fields())
+}
+
+var bigqueryTypes = map[report.FieldType]string{
+	report.StringField:  "STRING",
+	report.IntegerField: "INTEGER",
+	report.FloatField:   "FLOAT",
+	report.BooleanField: "BOOLEAN",
+	report.RecordField:  "RECORD",
+}
+
+func bigqueryFields(fields []report.Field) []*bigquery.TableFieldSchema {
+	schema := []*bigquery.TableFieldSchema{}
+	for _, f := range fields {
+		fs := &bigquery.TableFieldSchema{
+			Name: f.Name,
+			Type: bigqueryTypes[f.Type],
+			Mode: "NULLABLE",
+		}
+		if f.Repeated {
+			fs.Mode = "REPEATED"
+		} else if f.Required {
+			fs.Mode = "REQUIRED"
+		}
+		if f.Type == report.RecordField {
+			fs.Fields = bigqueryFields(f.Fields)
+		}
+		schema = append(schema, fs)
+	}
+	return schema
+}
+
 func makeRow(rec report.Record) map[string]bigquery.JsonValue {
 	row := map[string]bigquery.JsonValue{
 		"version":       rec.Version,
@@ -141,6 +176,36 @@ func makeRow(rec report.Record) map[string]bigquery.JsonValue {
 		extensions = append(extensions, makeExtension(e))
 	}
 	row["extensions"] = extensions
+	diagnostics := []map[string]bigquery.JsonValue{}
+	for _, d := range rec.ExtensionDiagnostics {
+		diagnostics = append(diagnostics, makeExtensionDiagnostic(d))
+	}
+	row["extensionDiagnostics"] = diagnostics
+	row["namespaces"] = makeNamespaces(rec.Namespaces)
+	lifetimes := []map[string]bigquery.JsonValue{}
+	for _, l := range rec.Lifetimes {
+		lifetimes = append(lifetimes, makeLifetime(l))
+	}
+	row["lifetimes"] = lifetimes
+	apiGroups := []map[string]bigquery.JsonValue{}
+	for _, g := range rec.APIGroups {
+		apiGroups = append(apiGroups, makeAPIGroup(g))
+	}
+	row["apiGroups"] = apiGroups
+	row["ecosystem"] = makeEcosystem(rec.Ecosystem)
+	row["topology"] = makeTopology(rec.Topology)
+	row["summary"] = makeSummary(rec.Summary)
+	row["storage"] = makeStorage(rec.Storage)
+	row["workloadFeatures"] = makeWorkloadFeatures(rec.WorkloadFeatures)
+	row["podSecurity"] = makePodSecurity(rec.PodSecurity)
+	row["networking"] = makeNetworking(rec.Networking)
+	row["images"] = makeImages(rec.Images)
+	row["distribution"] = makeKubernetesDistribution(rec.Distribution)
+	nodeGroups := []map[string]bigquery.JsonValue{}
+	for _, g := range rec.NodeGroups {
+		nodeGroups = append(nodeGroups, makeNodeGroup(g))
+	}
+	row["nodeGroups"] = nodeGroups
 	return row
 }
 
@@ -154,12 +219,37 @@ func makeNode(node report.Node) map[string]bigquery.JsonValue {
 		"containerRuntimeVersion": node.ContainerRuntimeVersion,
 		"kubeletVersion":          node.KubeletVersion,
 		"cloudProvider":           node.CloudProvider,
+		"unschedulable":           node.Unschedulable,
+		"region":                  node.Region,
+		"zone":                    node.Zone,
+		"instanceType":            node.InstanceType,
+		"containerRuntimeName":    node.ContainerRuntimeName,
+		"containerRuntimeSemver":  makeSemver(node.ContainerRuntimeSemver),
+		"kubeletSemver":           makeSemver(node.KubeletSemver),
 	}
 	capacity := []map[string]bigquery.JsonValue{}
 	for _, c := range node.Capacity {
 		capacity = append(capacity, makeResource(c))
 	}
 	n["capacity"] = capacity
+	allocatable := []map[string]bigquery.JsonValue{}
+	for _, a := range node.Allocatable {
+		allocatable = append(allocatable, makeResource(a))
+	}
+	n["allocatable"] = allocatable
+	conditions := []map[string]bigquery.JsonValue{}
+	for _, c := range node.Conditions {
+		conditions = append(conditions, makeNodeCondition(c))
+	}
+	n["conditions"] = conditions
+	taints := []map[string]bigquery.JsonValue{}
+	for _, t := range node.Taints {
+		taints = append(taints, makeTaintSummary(t))
+	}
+	n["taints"] = taints
+	roles := []string{}
+	roles = append(roles, node.Roles...)
+	n["roles"] = roles
 	return n
 }
 
@@ -171,10 +261,336 @@ func makeResource(res report.Resource) map[string]bigquery.JsonValue {
 	return r
 }
 
+func makeSemver(ver *report.Semver) map[string]bigquery.JsonValue {
+	if ver == nil {
+		return nil
+	}
+	v := map[string]bigquery.JsonValue{
+		"major":      ver.Major,
+		"minor":      ver.Minor,
+		"patch":      ver.Patch,
+		"prerelease": ver.Prerelease,
+	}
+	return v
+}
+
+func makeNodeGroup(group report.NodeGroup) map[string]bigquery.JsonValue {
+	g := map[string]bigquery.JsonValue{
+		"count":                   group.Count,
+		"operatingSystem":         group.OperatingSystem,
+		"osImage":                 group.OSImage,
+		"kernelVersion":           group.KernelVersion,
+		"architecture":            group.Architecture,
+		"containerRuntimeVersion": group.ContainerRuntimeVersion,
+		"kubeletVersion":          group.KubeletVersion,
+		"cloudProvider":           group.CloudProvider,
+	}
+	capacity := []map[string]bigquery.JsonValue{}
+	for _, c := range group.Capacity {
+		capacity = append(capacity, makeResource(c))
+	}
+	g["capacity"] = capacity
+	return g
+}
+
+func makeNodeCondition(cond report.NodeCondition) map[string]bigquery.JsonValue {
+	c := map[string]bigquery.JsonValue{
+		"type":   cond.Type,
+		"status": cond.Status,
+	}
+	return c
+}
+
+func makeTaintSummary(taint report.TaintSummary) map[string]bigquery.JsonValue {
+	t := map[string]bigquery.JsonValue{
+		"effect": taint.Effect,
+		"count":  taint.Count,
+	}
+	keyHashes := []string{}
+	keyHashes = append(keyHashes, taint.KeyHashes...)
+	t["keyHashes"] = keyHashes
+	return t
+}
+
 func makeExtension(ext report.Extension) map[string]bigquery.JsonValue {
+	// Typed values go in their own columns, so that queries need not cast
+	// the string form.
+	e := map[string]bigquery.JsonValue{
+		"name":       ext.Name,
+		"value":      ext.Value,
+		"type":       ext.Type,
+		"intValue":   ext.IntValue,
+		"floatValue": ext.FloatValue,
+		"boolValue":  ext.BoolValue,
+	}
+	return e
+}
+
+func makeExtensionDiagnostic(diag report.ExtensionDiagnostic) map[string]bigquery.JsonValue {
+	d := map[string]bigquery.JsonValue{
+		"source": diag.Source,
+		"count":  diag.Count,
+	}
+	errs := []string{}
+	errs = append(errs, diag.Errors...)
+	d["errors"] = errs
+	return d
+}
+
+func makeNamespaces(ns *report.Namespaces) map[string]bigquery.JsonValue {
+	if ns == nil {
+		return nil
+	}
+	n := map[string]bigquery.JsonValue{
+		"count": ns.Count,
+	}
+	histograms := []map[string]bigquery.JsonValue{}
+	for _, h := range ns.Histograms {
+		histograms = append(histograms, makeHistogram(h))
+	}
+	n["histograms"] = histograms
+	return n
+}
+
+func makeHistogram(hist report.Histogram) map[string]bigquery.JsonValue {
+	h := map[string]bigquery.JsonValue{
+		"kind": hist.Kind,
+	}
+	buckets := []map[string]bigquery.JsonValue{}
+	for _, b := range hist.Buckets {
+		buckets = append(buckets, makeBucket(b))
+	}
+	h["buckets"] = buckets
+	return h
+}
+
+func makeBucket(bucket report.Bucket) map[string]bigquery.JsonValue {
+	b := map[string]bigquery.JsonValue{
+		"min":   bucket.Min,
+		"max":   bucket.Max,
+		"count": bucket.Count,
+	}
+	return b
+}
+
+func makeLifetime(lifetime report.Lifetime) map[string]bigquery.JsonValue {
+	l := map[string]bigquery.JsonValue{
+		"kind":    lifetime.Kind,
+		"live":    makeDistribution(lifetime.Live),
+		"deleted": makeDistribution(lifetime.Deleted),
+	}
+	return l
+}
+
+func makeDistribution(dist *report.Distribution) map[string]bigquery.JsonValue {
+	if dist == nil {
+		return nil
+	}
+	d := map[string]bigquery.JsonValue{
+		"count":          dist.Count,
+		"averageSeconds": dist.AverageSeconds,
+	}
+	percentiles := []map[string]bigquery.JsonValue{}
+	for _, p := range dist.Percentiles {
+		percentiles = append(percentiles, makePercentile(p))
+	}
+	d["percentiles"] = percentiles
+	return d
+}
+
+func makePercentile(pct report.Percentile) map[string]bigquery.JsonValue {
+	p := map[string]bigquery.JsonValue{
+		"percentile": pct.Percentile,
+		"seconds":    pct.Seconds,
+	}
+	return p
+}
+
+func makeAPIGroup(group report.APIGroup) map[string]bigquery.JsonValue {
+	g := map[string]bigquery.JsonValue{
+		"name":             group.Name,
+		"preferredVersion": group.PreferredVersion,
+	}
+	versions := []string{}
+	versions = append(versions, group.Versions...)
+	g["versions"] = versions
+	return g
+}
+
+func makeEcosystem(eco *report.Ecosystem) map[string]bigquery.JsonValue {
+	if eco == nil {
+		return nil
+	}
 	e := map[string]bigquery.JsonValue{
-		"name":  ext.Name,
-		"value": ext.Value,
+		"catalogVersion":   eco.CatalogVersion,
+		"unknownAPIGroups": eco.UnknownAPIGroups,
 	}
+	projects := []string{}
+	projects = append(projects, eco.Projects...)
+	e["projects"] = projects
 	return e
 }
+
+func makeTopology(topo *report.Topology) map[string]bigquery.JsonValue {
+	if topo == nil {
+		return nil
+	}
+	t := map[string]bigquery.JsonValue{
+		"regionCount": topo.RegionCount,
+		"zoneCount":   topo.ZoneCount,
+	}
+	roles := []map[string]bigquery.JsonValue{}
+	for _, r := range topo.Roles {
+		roles = append(roles, makeRoleCount(r))
+	}
+	t["roles"] = roles
+	return t
+}
+
+func makeRoleCount(role report.RoleCount) map[string]bigquery.JsonValue {
+	r := map[string]bigquery.JsonValue{
+		"role":  role.Role,
+		"count": role.Count,
+	}
+	return r
+}
+
+func makeSummary(sum *report.Summary) map[string]bigquery.JsonValue {
+	if sum == nil {
+		return nil
+	}
+	s := map[string]bigquery.JsonValue{
+		"nodeCount":           sum.NodeCount,
+		"cpuCapacityMillis":   sum.CPUCapacityMillis,
+		"memoryCapacityBytes": sum.MemoryCapacityBytes,
+		"podsCapacity":        sum.PodsCapacity,
+		"kubeletVersions":     makeValueCounts(sum.KubeletVersions),
+		"osImages":            makeValueCounts(sum.OSImages),
+		"architectures":       makeValueCounts(sum.Architectures),
+	}
+	return s
+}
+
+func makeValueCounts(vcs []report.ValueCount) []map[string]bigquery.JsonValue {
+	rows := []map[string]bigquery.JsonValue{}
+	for _, vc := range vcs {
+		rows = append(rows, map[string]bigquery.JsonValue{
+			"value": vc.Value,
+			"count": vc.Count,
+		})
+	}
+	return rows
+}
+
+func makeStorage(st *report.Storage) map[string]bigquery.JsonValue {
+	if st == nil {
+		return nil
+	}
+	s := map[string]bigquery.JsonValue{
+		"classCount":             st.ClassCount,
+		"provisioners":           makeValueCounts(st.Provisioners),
+		"defaultClassCount":      st.DefaultClassCount,
+		"defaultProvisioner":     st.DefaultProvisioner,
+		"persistentVolumes":      makeVolumes(st.PersistentVolumes),
+		"persistentVolumeClaims": makeVolumes(st.PersistentVolumeClaims),
+	}
+	return s
+}
+
+func makeVolumes(vols *report.Volumes) map[string]bigquery.JsonValue {
+	if vols == nil {
+		return nil
+	}
+	v := map[string]bigquery.JsonValue{
+		"count":           vols.Count,
+		"accessModes":     makeValueCounts(vols.AccessModes),
+		"reclaimPolicies": makeValueCounts(vols.ReclaimPolicies),
+	}
+	capacity := []map[string]bigquery.JsonValue{}
+	for _, b := range vols.CapacityGiB {
+		capacity = append(capacity, makeBucket(b))
+	}
+	v["capacityGiB"] = capacity
+	return v
+}
+
+func makeWorkloadFeatures(wf *report.WorkloadFeatures) map[string]bigquery.JsonValue {
+	if wf == nil {
+		return nil
+	}
+	w := map[string]bigquery.JsonValue{
+		"horizontalPodAutoscalers": wf.HorizontalPodAutoscalers,
+		"podDisruptionBudgets":     wf.PodDisruptionBudgets,
+		"networkPolicies":          wf.NetworkPolicies,
+		"statefulSets":             wf.StatefulSets,
+		"daemonSets":               wf.DaemonSets,
+		"jobs":                     wf.Jobs,
+		"cronJobs":                 wf.CronJobs,
+		"ingresses":                wf.Ingresses,
+		"resourceQuotas":           wf.ResourceQuotas,
+		"limitRanges":              wf.LimitRanges,
+	}
+	return w
+}
+
+func makePodSecurity(ps *report.PodSecurity) map[string]bigquery.JsonValue {
+	if ps == nil {
+		return nil
+	}
+	p := map[string]bigquery.JsonValue{
+		"podCount":          ps.PodCount,
+		"hostNetwork":       ps.HostNetwork,
+		"hostPID":           ps.HostPID,
+		"privileged":        ps.Privileged,
+		"runAsNonRoot":      ps.RunAsNonRoot,
+		"hostPathVolumes":   ps.HostPathVolumes,
+		"addedCapabilities": ps.AddedCapabilities,
+	}
+	return p
+}
+
+func makeNetworking(net *report.Networking) map[string]bigquery.JsonValue {
+	if net == nil {
+		return nil
+	}
+	n := map[string]bigquery.JsonValue{
+		"serviceCount":  net.ServiceCount,
+		"serviceTypes":  makeValueCounts(net.ServiceTypes),
+		"ipFamily":      net.IPFamily,
+		"kubeProxyMode": net.KubeProxyMode,
+	}
+	plugins := []string{}
+	plugins = append(plugins, net.CNIPlugins...)
+	n["cniPlugins"] = plugins
+	return n
+}
+
+func makeImages(im *report.Images) map[string]bigquery.JsonValue {
+	if im == nil {
+		return nil
+	}
+	i := map[string]bigquery.JsonValue{
+		"containerCount": im.ContainerCount,
+		"registries":     makeValueCounts(im.Registries),
+		"digestCount":    im.DigestCount,
+		"tagCount":       im.TagCount,
+		"untaggedCount":  im.UntaggedCount,
+		"digestFraction": im.DigestFraction,
+	}
+	return i
+}
+
+func makeKubernetesDistribution(dist *report.KubernetesDistribution) map[string]bigquery.JsonValue {
+	if dist == nil {
+		return nil
+	}
+	d := map[string]bigquery.JsonValue{
+		"rulesVersion": dist.RulesVersion,
+		"name":         dist.Name,
+		"confidence":   dist.Confidence,
+	}
+	signals := []string{}
+	signals = append(signals, dist.Signals...)
+	d["signals"] = signals
+	return d
+}
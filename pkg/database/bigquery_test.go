@@ -0,0 +1,274 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+	bigquery "google.golang.org/api/bigquery/v2"
+)
+
+func strPtr(str string) *string {
+	return &str
+}
+
+// fullRecord returns a record with every field set, so that every column of
+// the schema is exercised.
+func fullRecord() report.Record {
+	return report.Record{
+		Version:       "v1.0.0",
+		Timestamp:     "1478020000",
+		ClusterID:     "cluster",
+		MasterVersion: strPtr("v1.4.6"),
+		Nodes: []report.Node{
+			{
+				ID:                      "node1",
+				OperatingSystem:         strPtr("linux"),
+				OSImage:                 strPtr("Debian GNU/Linux 7 (wheezy)"),
+				KernelVersion:           strPtr("3.16.0-4-amd64"),
+				Architecture:            strPtr("amd64"),
+				ContainerRuntimeVersion: strPtr("docker://1.11.2"),
+				KubeletVersion:          strPtr("v1.4.6"),
+				CloudProvider:           strPtr("aws"),
+				Capacity: []report.Resource{
+					{Resource: "cpu", Value: "4"},
+				},
+				Allocatable: []report.Resource{
+					{Resource: "cpu", Value: "3500m"},
+				},
+				Conditions: []report.NodeCondition{
+					{Type: "Ready", Status: "True"},
+				},
+				Unschedulable: boolPtr(false),
+				Taints: []report.TaintSummary{
+					{Effect: "NoSchedule", Count: 1, KeyHashes: []string{"5d41402abc4b2a76b9719d911017c592"}},
+				},
+				Region:                 strPtr("us-east-1"),
+				Zone:                   strPtr("us-east-1a"),
+				InstanceType:           strPtr("m4.large"),
+				Roles:                  []string{"master"},
+				ContainerRuntimeName:   strPtr("docker"),
+				ContainerRuntimeSemver: &report.Semver{Major: 1, Minor: 11, Patch: 2},
+				KubeletSemver:          &report.Semver{Major: 1, Minor: 4, Patch: 6},
+			},
+		},
+		Extensions: []report.Extension{
+			{Name: "example.com/hello", Value: "world", Type: "string"},
+			{Name: "example.com/replicas", Value: "3", Type: "int", IntValue: int64Ptr(3)},
+			{Name: "example.com/ratio", Value: "0.5", Type: "float", FloatValue: float64Ptr(0.5)},
+			{Name: "example.com/enabled", Value: "true", Type: "bool", BoolValue: boolPtr(true)},
+		},
+		ExtensionDiagnostics: []report.ExtensionDiagnostic{
+			{Source: "extensions.json", Count: 4, Errors: []string{`"foo": unsupported value [bar], must be a string, number, bool or object`}},
+		},
+		Namespaces: &report.Namespaces{
+			Count: 2,
+			Histograms: []report.Histogram{
+				{Kind: "pods", Buckets: []report.Bucket{{Min: 6, Max: int64Ptr(10), Count: 2}}},
+			},
+		},
+		Lifetimes: []report.Lifetime{
+			{
+				Kind: "pods",
+				Live: &report.Distribution{
+					Count:          2,
+					AverageSeconds: 90,
+					Percentiles:    []report.Percentile{{Percentile: 50, Seconds: 60}},
+				},
+				Deleted: &report.Distribution{
+					Count:          1,
+					AverageSeconds: 30,
+					Percentiles:    []report.Percentile{{Percentile: 50, Seconds: 30}},
+				},
+			},
+		},
+		APIGroups: []report.APIGroup{
+			{Name: "batch", Versions: []string{"v1", "v2alpha1"}, PreferredVersion: strPtr("v1")},
+		},
+		Ecosystem: &report.Ecosystem{
+			CatalogVersion:   "1",
+			Projects:         []string{"istio"},
+			UnknownAPIGroups: 1,
+		},
+		Topology: &report.Topology{
+			RegionCount: 1,
+			ZoneCount:   1,
+			Roles:       []report.RoleCount{{Role: "master", Count: 1}},
+		},
+		NodeGroups: []report.NodeGroup{
+			{
+				Count:                   2,
+				OperatingSystem:         strPtr("linux"),
+				OSImage:                 strPtr("Debian GNU/Linux 7 (wheezy)"),
+				KernelVersion:           strPtr("3.16.0-4-amd64"),
+				Architecture:            strPtr("amd64"),
+				ContainerRuntimeVersion: strPtr("docker://1.11.2"),
+				KubeletVersion:          strPtr("v1.4.6"),
+				CloudProvider:           strPtr("aws"),
+				Capacity:                []report.Resource{{Resource: "cpu", Value: "4"}},
+			},
+		},
+		Storage: &report.Storage{
+			ClassCount:         1,
+			Provisioners:       []report.ValueCount{{Value: "kubernetes.io/aws-ebs", Count: 1}},
+			DefaultClassCount:  1,
+			DefaultProvisioner: strPtr("kubernetes.io/aws-ebs"),
+			PersistentVolumes: &report.Volumes{
+				Count:           1,
+				AccessModes:     []report.ValueCount{{Value: "ReadWriteOnce", Count: 1}},
+				ReclaimPolicies: []report.ValueCount{{Value: "Delete", Count: 1}},
+				CapacityGiB:     []report.Bucket{{Min: 6, Max: int64Ptr(10), Count: 1}},
+			},
+			PersistentVolumeClaims: &report.Volumes{
+				Count:           1,
+				AccessModes:     []report.ValueCount{{Value: "ReadWriteOnce", Count: 1}},
+				ReclaimPolicies: []report.ValueCount{{Value: "Delete", Count: 1}},
+				CapacityGiB:     []report.Bucket{{Min: 6, Max: int64Ptr(10), Count: 1}},
+			},
+		},
+		WorkloadFeatures: &report.WorkloadFeatures{
+			HorizontalPodAutoscalers: int64Ptr(1),
+			PodDisruptionBudgets:     int64Ptr(2),
+			NetworkPolicies:          int64Ptr(3),
+			StatefulSets:             int64Ptr(4),
+			DaemonSets:               int64Ptr(5),
+			Jobs:                     int64Ptr(6),
+			CronJobs:                 int64Ptr(7),
+			Ingresses:                int64Ptr(8),
+			ResourceQuotas:           int64Ptr(9),
+			LimitRanges:              int64Ptr(10),
+		},
+		PodSecurity: &report.PodSecurity{
+			PodCount:          10,
+			HostNetwork:       1,
+			HostPID:           2,
+			Privileged:        3,
+			RunAsNonRoot:      4,
+			HostPathVolumes:   5,
+			AddedCapabilities: 6,
+		},
+		Networking: &report.Networking{
+			ServiceCount:  2,
+			ServiceTypes:  []report.ValueCount{{Value: "ClusterIP", Count: 1}, {Value: "NodePort", Count: 1}},
+			IPFamily:      strPtr("IPv4"),
+			KubeProxyMode: strPtr("ipvs"),
+			CNIPlugins:    []string{"flannel"},
+		},
+		Images: &report.Images{
+			ContainerCount: 4,
+			Registries:     []report.ValueCount{{Value: "docker.io", Count: 3}, {Value: "private", Count: 1}},
+			DigestCount:    1,
+			TagCount:       2,
+			UntaggedCount:  1,
+			DigestFraction: 0.25,
+		},
+		Distribution: &report.KubernetesDistribution{
+			RulesVersion: "1",
+			Name:         "gke",
+			Confidence:   "high",
+			Signals:      []string{"version", "nodeLabel"},
+		},
+		Summary: &report.Summary{
+			NodeCount:           1,
+			CPUCapacityMillis:   4000,
+			MemoryCapacityBytes: 1 << 30,
+			PodsCapacity:        110,
+			KubeletVersions:     []report.ValueCount{{Value: "v1.4.6", Count: 1}},
+			OSImages:            []report.ValueCount{{Value: "Debian GNU/Linux 7 (wheezy)", Count: 1}},
+			Architectures:       []report.ValueCount{{Value: "amd64", Count: 1}},
+		},
+	}
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestBigquerySchemaFile(t *testing.T) {
+	want, err := ioutil.ReadFile("bigquery.schema.json")
+	if err != nil {
+		t.Fatalf("failed to read schema file: %v", err)
+	}
+	got, err := json.MarshalIndent(BigquerySchema(), "", "  ")
+	if err != nil {
+		t.Fatalf("failed to encode schema: %v", err)
+	}
+	if !bytes.Equal(bytes.TrimSpace(want), got) {
+		t.Errorf("bigquery.schema.json is out of date, regenerate it with `spartakus schema --format=bigquery`; expected:\n%s", got)
+	}
+}
+
+func TestMakeRowMatchesSchema(t *testing.T) {
+	checkRow(t, "", makeRow(fullRecord()), BigquerySchema())
+}
+
+func checkRow(t *testing.T, path string, row map[string]bigquery.JsonValue, fields []*bigquery.TableFieldSchema) {
+	for _, f := range fields {
+		v, found := row[f.Name]
+		if !found {
+			t.Errorf("row is missing column %s%s", path, f.Name)
+			continue
+		}
+		if f.Type != "RECORD" {
+			continue
+		}
+		if f.Mode == "REPEATED" {
+			rows, ok := v.([]map[string]bigquery.JsonValue)
+			if !ok {
+				t.Errorf("column %s%s: expected a list of records, got %T", path, f.Name, v)
+				continue
+			}
+			if len(rows) == 0 {
+				t.Errorf("column %s%s: test record has no values", path, f.Name)
+			}
+			for _, r := range rows {
+				checkRow(t, path+f.Name+".", r, f.Fields)
+			}
+		} else {
+			r, ok := v.(map[string]bigquery.JsonValue)
+			if !ok {
+				t.Errorf("column %s%s: expected a record, got %T", path, f.Name, v)
+				continue
+			}
+			checkRow(t, path+f.Name+".", r, f.Fields)
+		}
+	}
+	for name := range row {
+		var found bool
+		for _, f := range fields {
+			if f.Name == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("row has column %s%s which is not in the schema", path, name)
+		}
+	}
+}
@@ -26,7 +26,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/gogo/protobuf/proto"
 	"github.com/kubernetes-incubator/spartakus/pkg/report"
+	reportv2 "github.com/kubernetes-incubator/spartakus/pkg/report/v2"
 	"github.com/thockin/logr"
 )
 
@@ -35,6 +37,9 @@ func init() {
 }
 
 // This plugin POSTS a JSON-encoded report.Record to a URL at /api/v1 path.
+// Adding "?api=v2" to the dbspec POSTs a reportv2.Record to /api/v2 instead.
+// Adding "?format=protobuf" sends the report.Record protobuf-encoded, which is
+// much more compact for large clusters.
 type httpPlugin struct{}
 
 func (plug httpPlugin) Attempt(log logr.Logger, dbspec string) (bool, Database, error) {
@@ -46,7 +51,7 @@ func (plug httpPlugin) Attempt(log logr.Logger, dbspec string) (bool, Database,
 	if err != nil {
 		return true, nil, fmt.Errorf("invalid http spec: %q: %v", dbspec, err)
 	}
-	db, err := newHTTPDatabase(newHTTPClient(), *url)
+	db, err := newHTTPDatabase(log, newHTTPClient(), *url)
 	return true, db, err
 }
 
@@ -63,29 +68,78 @@ func newHTTPClient() *http.Client {
 	return &http.Client{Transport: tr}
 }
 
-var urlPath = "/api/v1"
+var urlPaths = map[string]string{
+	"v1": "/api/v1",
+	"v2": "/api/v2",
+}
+
+func newHTTPDatabase(log logr.Logger, c *http.Client, u url.URL) (Database, error) {
+	api := u.Query().Get("api")
+	if api == "" {
+		api = "v1"
+	}
+	urlPath, found := urlPaths[api]
+	if !found {
+		return nil, fmt.Errorf("unknown API version: %q", api)
+	}
+	format := u.Query().Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "protobuf" {
+		return nil, fmt.Errorf("unknown format: %q", format)
+	}
+	if format == "protobuf" && api != "v1" {
+		return nil, fmt.Errorf("format %q is only supported by API v1", format)
+	}
 
-func newHTTPDatabase(c *http.Client, u url.URL) (Database, error) {
 	p, err := u.Parse(urlPath)
 	if err != nil {
 		return nil, fmt.Errorf("unable to prepare API URL: %v", err)
 	}
 
 	db := &httpDatabase{
+		log:    log,
 		client: c,
 		url:    p.String(),
+		api:    api,
+		format: format,
 	}
 
 	return db, nil
 }
 
 type httpDatabase struct {
+	log    logr.Logger
 	url    string
+	api    string
+	format string
 	client *http.Client
 }
 
-func (h *httpDatabase) Store(r report.Record) error {
+// encode returns the request body for r and its content type.
+func (h *httpDatabase) encode(r report.Record) ([]byte, string, error) {
+	if h.api == "v2" {
+		// A value that version 2 can not represent, such as a custom
+		// kubelet build's version, is left out rather than losing the
+		// whole report.
+		r2, errs := reportv2.ConvertFromV1Partial(r)
+		for _, err := range errs {
+			h.log.Errorf("left out of the version 2 report: %v", err)
+		}
+		body, err := json.Marshal(r2)
+		return body, "application/json", err
+	}
+	if h.format == "protobuf" {
+		body, err := proto.Marshal(&r)
+		return body, "application/x-protobuf", err
+	}
 	body, err := json.Marshal(r)
+	return body, "application/json", err
+}
+
+func (h *httpDatabase) Store(r report.Record) error {
+	body, contentType, err := h.encode(r)
 	if err != nil {
 		return fmt.Errorf("unable to encode HTTP request body: %v", err)
 	}
@@ -95,7 +149,7 @@ func (h *httpDatabase) Store(r report.Record) error {
 		return fmt.Errorf("unable to prepare HTTP request: %v", err)
 	}
 
-	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Content-Type", contentType)
 	res, err := h.client.Do(req)
 	if err != nil {
 		return fmt.Errorf("HTTP request failed: %v", err)
@@ -0,0 +1,137 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"github.com/gogo/protobuf/proto"
+)
+
+// The report types are encoded to and decoded from protobuf by reflection on
+// their `protobuf` struct tags, which must match report.proto.  These methods
+// make them proto.Messages.
+
+func (m *Record) Reset()         { *m = Record{} }
+func (m *Record) String() string { return proto.CompactTextString(m) }
+func (*Record) ProtoMessage()    {}
+
+func (m *Node) Reset()         { *m = Node{} }
+func (m *Node) String() string { return proto.CompactTextString(m) }
+func (*Node) ProtoMessage()    {}
+
+func (m *Resource) Reset()         { *m = Resource{} }
+func (m *Resource) String() string { return proto.CompactTextString(m) }
+func (*Resource) ProtoMessage()    {}
+
+func (m *Semver) Reset()         { *m = Semver{} }
+func (m *Semver) String() string { return proto.CompactTextString(m) }
+func (*Semver) ProtoMessage()    {}
+
+func (m *NodeCondition) Reset()         { *m = NodeCondition{} }
+func (m *NodeCondition) String() string { return proto.CompactTextString(m) }
+func (*NodeCondition) ProtoMessage()    {}
+
+func (m *TaintSummary) Reset()         { *m = TaintSummary{} }
+func (m *TaintSummary) String() string { return proto.CompactTextString(m) }
+func (*TaintSummary) ProtoMessage()    {}
+
+func (m *Extension) Reset()         { *m = Extension{} }
+func (m *Extension) String() string { return proto.CompactTextString(m) }
+func (*Extension) ProtoMessage()    {}
+
+func (m *ExtensionDiagnostic) Reset()         { *m = ExtensionDiagnostic{} }
+func (m *ExtensionDiagnostic) String() string { return proto.CompactTextString(m) }
+func (*ExtensionDiagnostic) ProtoMessage()    {}
+
+func (m *Namespaces) Reset()         { *m = Namespaces{} }
+func (m *Namespaces) String() string { return proto.CompactTextString(m) }
+func (*Namespaces) ProtoMessage()    {}
+
+func (m *Histogram) Reset()         { *m = Histogram{} }
+func (m *Histogram) String() string { return proto.CompactTextString(m) }
+func (*Histogram) ProtoMessage()    {}
+
+func (m *Bucket) Reset()         { *m = Bucket{} }
+func (m *Bucket) String() string { return proto.CompactTextString(m) }
+func (*Bucket) ProtoMessage()    {}
+
+func (m *Lifetime) Reset()         { *m = Lifetime{} }
+func (m *Lifetime) String() string { return proto.CompactTextString(m) }
+func (*Lifetime) ProtoMessage()    {}
+
+func (m *Distribution) Reset()         { *m = Distribution{} }
+func (m *Distribution) String() string { return proto.CompactTextString(m) }
+func (*Distribution) ProtoMessage()    {}
+
+func (m *Percentile) Reset()         { *m = Percentile{} }
+func (m *Percentile) String() string { return proto.CompactTextString(m) }
+func (*Percentile) ProtoMessage()    {}
+
+func (m *APIGroup) Reset()         { *m = APIGroup{} }
+func (m *APIGroup) String() string { return proto.CompactTextString(m) }
+func (*APIGroup) ProtoMessage()    {}
+
+func (m *Ecosystem) Reset()         { *m = Ecosystem{} }
+func (m *Ecosystem) String() string { return proto.CompactTextString(m) }
+func (*Ecosystem) ProtoMessage()    {}
+
+func (m *Topology) Reset()         { *m = Topology{} }
+func (m *Topology) String() string { return proto.CompactTextString(m) }
+func (*Topology) ProtoMessage()    {}
+
+func (m *RoleCount) Reset()         { *m = RoleCount{} }
+func (m *RoleCount) String() string { return proto.CompactTextString(m) }
+func (*RoleCount) ProtoMessage()    {}
+
+func (m *Summary) Reset()         { *m = Summary{} }
+func (m *Summary) String() string { return proto.CompactTextString(m) }
+func (*Summary) ProtoMessage()    {}
+
+func (m *ValueCount) Reset()         { *m = ValueCount{} }
+func (m *ValueCount) String() string { return proto.CompactTextString(m) }
+func (*ValueCount) ProtoMessage()    {}
+
+func (m *NodeGroup) Reset()         { *m = NodeGroup{} }
+func (m *NodeGroup) String() string { return proto.CompactTextString(m) }
+func (*NodeGroup) ProtoMessage()    {}
+
+func (m *Storage) Reset()         { *m = Storage{} }
+func (m *Storage) String() string { return proto.CompactTextString(m) }
+func (*Storage) ProtoMessage()    {}
+
+func (m *Volumes) Reset()         { *m = Volumes{} }
+func (m *Volumes) String() string { return proto.CompactTextString(m) }
+func (*Volumes) ProtoMessage()    {}
+
+func (m *WorkloadFeatures) Reset()         { *m = WorkloadFeatures{} }
+func (m *WorkloadFeatures) String() string { return proto.CompactTextString(m) }
+func (*WorkloadFeatures) ProtoMessage()    {}
+
+func (m *PodSecurity) Reset()         { *m = PodSecurity{} }
+func (m *PodSecurity) String() string { return proto.CompactTextString(m) }
+func (*PodSecurity) ProtoMessage()    {}
+
+func (m *Networking) Reset()         { *m = Networking{} }
+func (m *Networking) String() string { return proto.CompactTextString(m) }
+func (*Networking) ProtoMessage()    {}
+
+func (m *Images) Reset()         { *m = Images{} }
+func (m *Images) String() string { return proto.CompactTextString(m) }
+func (*Images) ProtoMessage()    {}
+
+func (m *KubernetesDistribution) Reset()         { *m = KubernetesDistribution{} }
+func (m *KubernetesDistribution) String() string { return proto.CompactTextString(m) }
+func (*KubernetesDistribution) ProtoMessage()    {}
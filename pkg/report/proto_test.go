@@ -0,0 +1,83 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestProtoRoundTrip(t *testing.T) {
+	testCases := []Record{
+		{},
+		validRecord(),
+	}
+
+	for i, tc := range testCases {
+		b, err := proto.Marshal(&tc)
+		if err != nil {
+			t.Errorf("[%d] failed to encode: %v", i, err)
+			continue
+		}
+		var rec Record
+		if err := proto.Unmarshal(b, &rec); err != nil {
+			t.Errorf("[%d] failed to decode: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(rec, tc) {
+			t.Errorf("[%d] did not get expected result:\n%s", i, pretty.Compare(rec, tc))
+		}
+	}
+}
+
+// TestProtoTags makes sure that every field of the report types can be
+// encoded, under the same name as in JSON and with a unique field number.
+func TestProtoTags(t *testing.T) {
+	checkProtoTags(t, reflect.TypeOf(Record{}))
+}
+
+func checkProtoTags(t *testing.T, typ reflect.Type) {
+	numbers := map[string]bool{}
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		tag := strings.Split(sf.Tag.Get("protobuf"), ",")
+		if len(tag) < 4 {
+			t.Errorf("%s.%s: missing or malformed protobuf tag", typ.Name(), sf.Name)
+			continue
+		}
+		if numbers[tag[1]] {
+			t.Errorf("%s.%s: duplicate protobuf field number %s", typ.Name(), sf.Name, tag[1])
+		}
+		numbers[tag[1]] = true
+		jsonName := strings.Split(sf.Tag.Get("json"), ",")[0]
+		if tag[3] != "name="+jsonName {
+			t.Errorf("%s.%s: protobuf %s does not match JSON name %q", typ.Name(), sf.Name, tag[3], jsonName)
+		}
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr || ft.Kind() == reflect.Slice {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct {
+			checkProtoTags(t, ft)
+		}
+	}
+}
@@ -18,22 +18,65 @@ package report
 
 type Record struct {
 	// Version is the version.VERSION of the schema being reported.
-	Version string `json:"version"` // required
+	Version string `json:"version" protobuf:"bytes,1,opt,name=version"` // required
 	// Timestamp is the UNIX timestamp when the report was received.
-	Timestamp string `json:"timestamp"` // provided by server, client values are ignored
+	Timestamp string `json:"timestamp" protobuf:"bytes,2,opt,name=timestamp" schema:"server"` // provided by server, client values are ignored
 	// ClusterID is a string reported by the volunteer.  It could be anything but
 	// a random GUID is strongly recommended. This should be a stable value for
 	// the lifetime of the cluster, or else reports will not be assumed to be
 	// the same cluster.  This must not include personally identifiable
 	// information.
-	ClusterID string `json:"clusterID"` // required
+	ClusterID string `json:"clusterID" protobuf:"bytes,3,opt,name=clusterID"` // required
 	// MasterVersion is the version string of the kubernetes master in the
 	// reporting cluster.
-	MasterVersion *string `json:"masterVersion,omitempty"`
+	MasterVersion *string `json:"masterVersion,omitempty" protobuf:"bytes,4,opt,name=masterVersion"`
 	// Nodes is a list of node-specific information from the reporting cluster.
-	Nodes []Node `json:"nodes,omitempty"`
+	Nodes []Node `json:"nodes,omitempty" protobuf:"bytes,5,rep,name=nodes"`
 	// Extensions is a list of key-value pairs of custom values.
-	Extensions []Extension `json:"extensions,omitempty"`
+	Extensions []Extension `json:"extensions,omitempty" protobuf:"bytes,6,rep,name=extensions"`
+	// Namespaces is information about the namespaces in the reporting
+	// cluster.
+	Namespaces *Namespaces `json:"namespaces,omitempty" protobuf:"bytes,7,opt,name=namespaces"`
+	// Lifetimes is a list of lifetime statistics, one per kind of object.
+	Lifetimes []Lifetime `json:"lifetimes,omitempty" protobuf:"bytes,8,rep,name=lifetimes"`
+	// APIGroups is a list of the API groups served by the kubernetes master
+	// in the reporting cluster.
+	APIGroups []APIGroup `json:"apiGroups,omitempty" protobuf:"bytes,9,rep,name=apiGroups"`
+	// Ecosystem is the set of well-known add-on projects detected in the
+	// reporting cluster.
+	Ecosystem *Ecosystem `json:"ecosystem,omitempty" protobuf:"bytes,10,opt,name=ecosystem"`
+	// Topology is a summary of how the nodes of the reporting cluster are
+	// spread over regions and zones, and of their roles.
+	Topology *Topology `json:"topology,omitempty" protobuf:"bytes,11,opt,name=topology"`
+	// Summary is an aggregate of the nodes of the reporting cluster, so that
+	// simple queries do not need to look at every node.
+	Summary *Summary `json:"summary,omitempty" protobuf:"bytes,12,opt,name=summary"`
+	// NodeGroups is a list of groups of nodes with identical attributes.  It
+	// is reported instead of Nodes when the volunteer groups nodes, which
+	// keeps reports from very large clusters small.
+	NodeGroups []NodeGroup `json:"nodeGroups,omitempty" protobuf:"bytes,13,rep,name=nodeGroups"`
+	// Storage is information about the storage classes and persistent
+	// volumes in the reporting cluster.
+	Storage *Storage `json:"storage,omitempty" protobuf:"bytes,14,opt,name=storage"`
+	// WorkloadFeatures is the number of objects of each feature-bearing kind,
+	// such as HorizontalPodAutoscalers, in the reporting cluster.
+	WorkloadFeatures *WorkloadFeatures `json:"workloadFeatures,omitempty" protobuf:"bytes,15,opt,name=workloadFeatures"`
+	// PodSecurity is the number of pods that use security-relevant settings,
+	// such as privileged containers, in the reporting cluster.
+	PodSecurity *PodSecurity `json:"podSecurity,omitempty" protobuf:"bytes,16,opt,name=podSecurity"`
+	// Networking is information about the services and the network setup of
+	// the reporting cluster.
+	Networking *Networking `json:"networking,omitempty" protobuf:"bytes,17,opt,name=networking"`
+	// Images is information about where the container images of the pods in
+	// the reporting cluster come from.  Image names are never reported.
+	Images *Images `json:"images,omitempty" protobuf:"bytes,18,opt,name=images"`
+	// Distribution is the kubernetes distribution that the reporting cluster
+	// was detected to run, such as GKE, OpenShift or k3s.
+	Distribution *KubernetesDistribution `json:"distribution,omitempty" protobuf:"bytes,19,opt,name=distribution"`
+	// ExtensionDiagnostics is a list of diagnostics, one per source of
+	// extensions that the volunteer read.  It is only reported if the
+	// volunteer is asked to.
+	ExtensionDiagnostics []ExtensionDiagnostic `json:"extensionDiagnostics,omitempty" protobuf:"bytes,20,rep,name=extensionDiagnostics"`
 }
 
 type Node struct {
@@ -42,38 +85,431 @@ type Node struct {
 	// identifying information.  This should be a stable value for the lifetime
 	// of the node, or else it will be assumed to be a different node.  This
 	// must not include personally identifiable information.
-	ID string `json:"id"` // required
+	ID string `json:"id" protobuf:"bytes,1,opt,name=id"` // required
 	// OperatingSystem is the value reported by kubernetes in the node status.
-	OperatingSystem *string `json:"operatingSystem,omitempty"`
+	OperatingSystem *string `json:"operatingSystem,omitempty" protobuf:"bytes,2,opt,name=operatingSystem"`
 	// OSImage is the value reported by kubernetes in the node status.
-	OSImage *string `json:"osImage,omitempty"`
+	OSImage *string `json:"osImage,omitempty" protobuf:"bytes,3,opt,name=osImage"`
 	// KernelVersion is the value reported by kubernetes in the node status.
-	KernelVersion *string `json:"kernelVersion,omitempty"`
+	KernelVersion *string `json:"kernelVersion,omitempty" protobuf:"bytes,4,opt,name=kernelVersion"`
 	// Architecture is the value reported by kubernetes in the node status.
-	Architecture *string `json:"architecture,omitempty"`
+	Architecture *string `json:"architecture,omitempty" protobuf:"bytes,5,opt,name=architecture"`
 	// ContainerRuntimeVersion is the value reported by kubernetes in the node
 	// status.
-	ContainerRuntimeVersion *string `json:"containerRuntimeVersion,omitempty"`
+	ContainerRuntimeVersion *string `json:"containerRuntimeVersion,omitempty" protobuf:"bytes,6,opt,name=containerRuntimeVersion"`
 	// KubeletVersion is the value reported by kubernetes in the node status.
-	KubeletVersion *string `json:"kubeletVersion,omitempty"`
-	// CloudProvider is the <ProviderName> portion of the ProviderID reported
-	// by kubernetes in the node spec.
-	CloudProvider *string `json:"cloudProvider,omitempty"`
+	KubeletVersion *string `json:"kubeletVersion,omitempty" protobuf:"bytes,7,opt,name=kubeletVersion"`
+	// CloudProvider is the cloud provider of the node, detected from the
+	// <ProviderName> portion of the ProviderID reported by kubernetes in the
+	// node spec, or from well-known labels and annotations.  Unknown
+	// providers are hashed.
+	CloudProvider *string `json:"cloudProvider,omitempty" protobuf:"bytes,8,opt,name=cloudProvider"`
 	// Capacity is a list of resources and their associated values as reported
 	// by kubernetes in the node status.
-	Capacity []Resource `json:"capacity,omitempty"`
+	Capacity []Resource `json:"capacity,omitempty" protobuf:"bytes,9,rep,name=capacity"`
+	// Allocatable is a list of resources and their associated values that are
+	// available for scheduling, as reported by kubernetes in the node status.
+	Allocatable []Resource `json:"allocatable,omitempty" protobuf:"bytes,10,rep,name=allocatable"`
+	// Conditions is a list of the node's current conditions, as reported by
+	// kubernetes in the node status.
+	Conditions []NodeCondition `json:"conditions,omitempty" protobuf:"bytes,11,rep,name=conditions"`
+	// Unschedulable is the value reported by kubernetes in the node spec.
+	Unschedulable *bool `json:"unschedulable,omitempty" protobuf:"varint,12,opt,name=unschedulable"`
+	// Taints is a summary of the node's taints, one entry per effect.
+	Taints []TaintSummary `json:"taints,omitempty" protobuf:"bytes,13,rep,name=taints"`
+	// Region is the node's region label.  Well-known cloud regions are
+	// reported verbatim, anything else is hashed.
+	Region *string `json:"region,omitempty" protobuf:"bytes,14,opt,name=region"`
+	// Zone is the node's zone label.  Well-known cloud zones are reported
+	// verbatim, anything else is hashed.
+	Zone *string `json:"zone,omitempty" protobuf:"bytes,15,opt,name=zone"`
+	// InstanceType is the node's instance type label.  Well-known cloud
+	// instance types are reported verbatim, anything else is hashed.
+	InstanceType *string `json:"instanceType,omitempty" protobuf:"bytes,16,opt,name=instanceType"`
+	// Roles is a list of the node's roles, from its role labels.  Roles that
+	// are not well-known are reported as "other".
+	Roles []string `json:"roles,omitempty" protobuf:"bytes,17,rep,name=roles"`
+	// ContainerRuntimeName is the <RuntimeName> portion of
+	// ContainerRuntimeVersion, which has the form <RuntimeName>://<Version>.
+	ContainerRuntimeName *string `json:"containerRuntimeName,omitempty" protobuf:"bytes,18,opt,name=containerRuntimeName"`
+	// ContainerRuntimeSemver is the <Version> portion of
+	// ContainerRuntimeVersion, parsed as a semantic version.  It is not set
+	// if the version can not be parsed.
+	ContainerRuntimeSemver *Semver `json:"containerRuntimeSemver,omitempty" protobuf:"bytes,19,opt,name=containerRuntimeSemver"`
+	// KubeletSemver is KubeletVersion parsed as a semantic version.  It is
+	// not set if the version can not be parsed.
+	KubeletSemver *Semver `json:"kubeletSemver,omitempty" protobuf:"bytes,20,opt,name=kubeletSemver"`
 }
 
 type Resource struct {
 	// Resource is the name of the resource.
-	Resource string `json:"resource"` // required
+	Resource string `json:"resource" protobuf:"bytes,1,opt,name=resource"` // required
 	// Value is the string form of the of the resource's value.
-	Value string `json:"value"` // required
+	Value string `json:"value" protobuf:"bytes,2,opt,name=value"` // required
+}
+
+type Semver struct {
+	// Major is the major version.
+	Major int64 `json:"major" protobuf:"varint,1,opt,name=major"` // required
+	// Minor is the minor version.
+	Minor int64 `json:"minor" protobuf:"varint,2,opt,name=minor"` // required
+	// Patch is the patch version.
+	Patch int64 `json:"patch" protobuf:"varint,3,opt,name=patch"` // required
+	// Prerelease is the pre-release part of the version, e.g. "alpha.2".
+	Prerelease *string `json:"prerelease,omitempty" protobuf:"bytes,4,opt,name=prerelease"`
+}
+
+type NodeCondition struct {
+	// Type is the type of the condition, e.g. "Ready" or "MemoryPressure".
+	Type string `json:"type" protobuf:"bytes,1,opt,name=type"` // required
+	// Status is the status of the condition: "True", "False" or "Unknown".
+	Status string `json:"status" protobuf:"bytes,2,opt,name=status"` // required
+}
+
+type TaintSummary struct {
+	// Effect is the effect of the taints, e.g. "NoSchedule".
+	Effect string `json:"effect" protobuf:"bytes,1,opt,name=effect"` // required
+	// Count is the number of taints with this effect.
+	Count int64 `json:"count" protobuf:"varint,2,opt,name=count"` // required
+	// KeyHashes is a list of hashes of the keys of the taints with this
+	// effect.  Keys are hashed because they can be anything.
+	KeyHashes []string `json:"keyHashes,omitempty" protobuf:"bytes,3,rep,name=keyHashes"`
 }
 
 type Extension struct {
 	// Name is the name of the extension.
-	Name string `json:"name"` // required
+	Name string `json:"name" protobuf:"bytes,1,opt,name=name"` // required
 	// Value is the string form of the of the extension's value.
-	Value string `json:"value"` // required
+	Value string `json:"value" protobuf:"bytes,2,opt,name=value"` // required
+	// Type is the type of the extension's value: "string", "int", "float" or
+	// "bool".  It is not set by older volunteers, whose values are all
+	// strings.
+	Type string `json:"type,omitempty" protobuf:"bytes,3,opt,name=type"`
+	// IntValue is the value of an "int" extension.
+	IntValue *int64 `json:"intValue,omitempty" protobuf:"varint,4,opt,name=intValue"`
+	// FloatValue is the value of a "float" extension.
+	FloatValue *float64 `json:"floatValue,omitempty" protobuf:"fixed64,5,opt,name=floatValue"`
+	// BoolValue is the value of a "bool" extension.
+	BoolValue *bool `json:"boolValue,omitempty" protobuf:"varint,6,opt,name=boolValue"`
+}
+
+type ExtensionDiagnostic struct {
+	// Source is where the extensions were read from, e.g. the name of a file.
+	Source string `json:"source" protobuf:"bytes,1,opt,name=source"` // required
+	// Count is the number of extensions that were read from the source and
+	// reported.
+	Count int64 `json:"count" protobuf:"varint,2,opt,name=count"` // required
+	// Errors is a list of the problems found in the source, such as invalid
+	// or duplicate keys.  Extensions with problems are not reported.
+	Errors []string `json:"errors,omitempty" protobuf:"bytes,3,rep,name=errors"`
+}
+
+// The types of extension values, as in Extension.Type.
+const (
+	ExtensionTypeString = "string"
+	ExtensionTypeInt    = "int"
+	ExtensionTypeFloat  = "float"
+	ExtensionTypeBool   = "bool"
+)
+
+type Namespaces struct {
+	// Count is the number of namespaces in the cluster.
+	Count int64 `json:"count" protobuf:"varint,1,opt,name=count"` // required
+	// Histograms is a list of histograms, one per kind of object, of how many
+	// objects of that kind exist in each namespace.  Namespace names are never
+	// reported.
+	Histograms []Histogram `json:"histograms,omitempty" protobuf:"bytes,2,rep,name=histograms"`
+}
+
+type Histogram struct {
+	// Kind is the kind of object being counted, e.g. "pods".
+	Kind string `json:"kind" protobuf:"bytes,1,opt,name=kind"` // required
+	// Buckets is a list of the non-empty buckets of the histogram, in
+	// increasing order.
+	Buckets []Bucket `json:"buckets,omitempty" protobuf:"bytes,2,rep,name=buckets"`
+}
+
+type Bucket struct {
+	// Min is the smallest value counted in this bucket.
+	Min int64 `json:"min" protobuf:"varint,1,opt,name=min"` // required
+	// Max is the largest value counted in this bucket.  It is not set for the
+	// last bucket, which has no upper bound.
+	Max *int64 `json:"max,omitempty" protobuf:"varint,2,opt,name=max"`
+	// Count is the number of values in this bucket.
+	Count int64 `json:"count" protobuf:"varint,3,opt,name=count"` // required
+}
+
+type Lifetime struct {
+	// Kind is the kind of object being measured, e.g. "pods".
+	Kind string `json:"kind" protobuf:"bytes,1,opt,name=kind"` // required
+	// Live is the distribution of the ages of the objects that existed when
+	// the report was generated.
+	Live *Distribution `json:"live,omitempty" protobuf:"bytes,2,opt,name=live"`
+	// Deleted is the distribution of the lifetimes of the objects that were
+	// deleted since the previous report.  An object's lifetime is measured
+	// up to the previous report, the last time it was seen, so it is a lower
+	// bound.  It is not set when there was no previous report to compare
+	// against.
+	Deleted *Distribution `json:"deleted,omitempty" protobuf:"bytes,3,opt,name=deleted"`
+}
+
+type Distribution struct {
+	// Count is the number of objects measured.
+	Count int64 `json:"count" protobuf:"varint,1,opt,name=count"` // required
+	// AverageSeconds is the mean of the measured durations, in seconds.
+	AverageSeconds int64 `json:"averageSeconds" protobuf:"varint,2,opt,name=averageSeconds"` // required
+	// Percentiles is a list of percentiles of the measured durations, in
+	// increasing order.
+	Percentiles []Percentile `json:"percentiles,omitempty" protobuf:"bytes,3,rep,name=percentiles"`
+}
+
+type Percentile struct {
+	// Percentile is the percentile being reported, from 1 to 100.
+	Percentile int64 `json:"percentile" protobuf:"varint,1,opt,name=percentile"` // required
+	// Seconds is the duration at that percentile, in seconds.
+	Seconds int64 `json:"seconds" protobuf:"varint,2,opt,name=seconds"` // required
+}
+
+type APIGroup struct {
+	// Name is the name of the group, e.g. "batch".  The legacy core group,
+	// served under /api, is named "".
+	Name string `json:"name" protobuf:"bytes,1,opt,name=name"`
+	// Versions is a list of the versions of the group that are served, e.g.
+	// "v1" or "v2alpha1", as reported by kubernetes discovery.
+	Versions []string `json:"versions,omitempty" protobuf:"bytes,2,rep,name=versions"`
+	// PreferredVersion is the version of the group preferred by the master.
+	PreferredVersion *string `json:"preferredVersion,omitempty" protobuf:"bytes,3,opt,name=preferredVersion"`
+}
+
+type Ecosystem struct {
+	// CatalogVersion is the version of the volunteer's catalog of known
+	// projects that was used for detection.  A project that is not in the
+	// catalog can never be detected.
+	CatalogVersion string `json:"catalogVersion" protobuf:"bytes,1,opt,name=catalogVersion"` // required
+	// Projects is a list of the identifiers of the projects that were
+	// detected, e.g. "istio".
+	Projects []string `json:"projects,omitempty" protobuf:"bytes,2,rep,name=projects"`
+	// UnknownAPIGroups is the number of API groups that are neither built
+	// into kubernetes nor in the catalog.  Their names are never reported.
+	UnknownAPIGroups int64 `json:"unknownAPIGroups" protobuf:"varint,3,opt,name=unknownAPIGroups"` // required
+}
+
+type Topology struct {
+	// RegionCount is the number of distinct regions that nodes are in.
+	RegionCount int64 `json:"regionCount" protobuf:"varint,1,opt,name=regionCount"` // required
+	// ZoneCount is the number of distinct zones that nodes are in.
+	ZoneCount int64 `json:"zoneCount" protobuf:"varint,2,opt,name=zoneCount"` // required
+	// Roles is a list of the node roles and how many nodes have each of them.
+	Roles []RoleCount `json:"roles,omitempty" protobuf:"bytes,3,rep,name=roles"`
+}
+
+type RoleCount struct {
+	// Role is the name of the role, as in Node.Roles.
+	Role string `json:"role" protobuf:"bytes,1,opt,name=role"` // required
+	// Count is the number of nodes with the role.
+	Count int64 `json:"count" protobuf:"varint,2,opt,name=count"` // required
+}
+
+type Summary struct {
+	// NodeCount is the number of nodes.
+	NodeCount int64 `json:"nodeCount" protobuf:"varint,1,opt,name=nodeCount"` // required
+	// CPUCapacityMillis is the total CPU capacity of the nodes, in
+	// millicores.
+	CPUCapacityMillis int64 `json:"cpuCapacityMillis" protobuf:"varint,2,opt,name=cpuCapacityMillis"` // required
+	// MemoryCapacityBytes is the total memory capacity of the nodes, in
+	// bytes.
+	MemoryCapacityBytes int64 `json:"memoryCapacityBytes" protobuf:"varint,3,opt,name=memoryCapacityBytes"` // required
+	// PodsCapacity is the total number of pods that the nodes can run.
+	PodsCapacity int64 `json:"podsCapacity" protobuf:"varint,4,opt,name=podsCapacity"` // required
+	// KubeletVersions is a list of the distinct kubelet versions of the
+	// nodes, and how many nodes run each of them.
+	KubeletVersions []ValueCount `json:"kubeletVersions,omitempty" protobuf:"bytes,5,rep,name=kubeletVersions"`
+	// OSImages is a list of the distinct OS images of the nodes, and how many
+	// nodes run each of them.
+	OSImages []ValueCount `json:"osImages,omitempty" protobuf:"bytes,6,rep,name=osImages"`
+	// Architectures is a list of the distinct architectures of the nodes, and
+	// how many nodes have each of them.
+	Architectures []ValueCount `json:"architectures,omitempty" protobuf:"bytes,7,rep,name=architectures"`
+}
+
+type ValueCount struct {
+	// Value is the value being counted.
+	Value string `json:"value" protobuf:"bytes,1,opt,name=value"` // required
+	// Count is the number of times the value occurs.
+	Count int64 `json:"count" protobuf:"varint,2,opt,name=count"` // required
+}
+
+type NodeGroup struct {
+	// Count is the number of nodes in the group.
+	Count int64 `json:"count" protobuf:"varint,1,opt,name=count"` // required
+	// OperatingSystem is the value reported by kubernetes in the status of
+	// the nodes.
+	OperatingSystem *string `json:"operatingSystem,omitempty" protobuf:"bytes,2,opt,name=operatingSystem"`
+	// OSImage is the value reported by kubernetes in the status of the nodes.
+	OSImage *string `json:"osImage,omitempty" protobuf:"bytes,3,opt,name=osImage"`
+	// KernelVersion is the value reported by kubernetes in the status of the
+	// nodes.
+	KernelVersion *string `json:"kernelVersion,omitempty" protobuf:"bytes,4,opt,name=kernelVersion"`
+	// Architecture is the value reported by kubernetes in the status of the
+	// nodes.
+	Architecture *string `json:"architecture,omitempty" protobuf:"bytes,5,opt,name=architecture"`
+	// ContainerRuntimeVersion is the value reported by kubernetes in the
+	// status of the nodes.
+	ContainerRuntimeVersion *string `json:"containerRuntimeVersion,omitempty" protobuf:"bytes,6,opt,name=containerRuntimeVersion"`
+	// KubeletVersion is the value reported by kubernetes in the status of the
+	// nodes.
+	KubeletVersion *string `json:"kubeletVersion,omitempty" protobuf:"bytes,7,opt,name=kubeletVersion"`
+	// CloudProvider is the cloud provider of the nodes, detected from the
+	// <ProviderName> portion of the ProviderID reported by kubernetes in the
+	// spec of the nodes, or from well-known labels and annotations.  Unknown
+	// providers are hashed.
+	CloudProvider *string `json:"cloudProvider,omitempty" protobuf:"bytes,8,opt,name=cloudProvider"`
+	// Capacity is a list of resources and their associated values as reported
+	// by kubernetes in the status of the nodes.
+	Capacity []Resource `json:"capacity,omitempty" protobuf:"bytes,9,rep,name=capacity"`
+}
+
+type Storage struct {
+	// ClassCount is the number of storage classes.  Their names are not
+	// reported.
+	ClassCount int64 `json:"classCount" protobuf:"varint,1,opt,name=classCount"` // required
+	// Provisioners is a list of the distinct provisioners of the storage
+	// classes, and how many classes use each of them.  In-tree and well-known
+	// CSI provisioners are reported as they are; anything else is hashed.
+	Provisioners []ValueCount `json:"provisioners,omitempty" protobuf:"bytes,2,rep,name=provisioners"`
+	// DefaultClassCount is the number of storage classes marked as the
+	// default.  More than one is a misconfiguration.
+	DefaultClassCount int64 `json:"defaultClassCount" protobuf:"varint,3,opt,name=defaultClassCount"` // required
+	// DefaultProvisioner is the provisioner of the default storage class,
+	// reported like Provisioners.  It is not set if there is no default, or
+	// more than one.
+	DefaultProvisioner *string `json:"defaultProvisioner,omitempty" protobuf:"bytes,4,opt,name=defaultProvisioner"`
+	// PersistentVolumes is information about the persistent volumes.
+	PersistentVolumes *Volumes `json:"persistentVolumes,omitempty" protobuf:"bytes,5,opt,name=persistentVolumes"`
+	// PersistentVolumeClaims is information about the persistent volume
+	// claims.
+	PersistentVolumeClaims *Volumes `json:"persistentVolumeClaims,omitempty" protobuf:"bytes,6,opt,name=persistentVolumeClaims"`
+}
+
+type Volumes struct {
+	// Count is the number of volumes or claims.
+	Count int64 `json:"count" protobuf:"varint,1,opt,name=count"` // required
+	// AccessModes is a list of access modes, such as "ReadWriteOnce", and how
+	// many volumes or claims have each of them.  A volume with several access
+	// modes is counted once for each.
+	AccessModes []ValueCount `json:"accessModes,omitempty" protobuf:"bytes,2,rep,name=accessModes"`
+	// ReclaimPolicies is a list of reclaim policies, such as "Delete", and
+	// how many volumes have each of them.  It is only set for persistent
+	// volumes.
+	ReclaimPolicies []ValueCount `json:"reclaimPolicies,omitempty" protobuf:"bytes,3,rep,name=reclaimPolicies"`
+	// CapacityGiB is a histogram of the capacity of the volumes, or of the
+	// storage requested by the claims, in GiB rounded up.  Only non-empty
+	// buckets are reported.
+	CapacityGiB []Bucket `json:"capacityGiB,omitempty" protobuf:"bytes,4,rep,name=capacityGiB"`
+}
+
+// WorkloadFeatures is the number of objects of each feature-bearing kind
+// across all namespaces.  A count is not set if the reporting cluster does not
+// serve that kind.
+type WorkloadFeatures struct {
+	// HorizontalPodAutoscalers is the number of HorizontalPodAutoscalers.
+	HorizontalPodAutoscalers *int64 `json:"horizontalPodAutoscalers,omitempty" protobuf:"varint,1,opt,name=horizontalPodAutoscalers"`
+	// PodDisruptionBudgets is the number of PodDisruptionBudgets.
+	PodDisruptionBudgets *int64 `json:"podDisruptionBudgets,omitempty" protobuf:"varint,2,opt,name=podDisruptionBudgets"`
+	// NetworkPolicies is the number of NetworkPolicies.
+	NetworkPolicies *int64 `json:"networkPolicies,omitempty" protobuf:"varint,3,opt,name=networkPolicies"`
+	// StatefulSets is the number of StatefulSets (PetSets in older versions).
+	StatefulSets *int64 `json:"statefulSets,omitempty" protobuf:"varint,4,opt,name=statefulSets"`
+	// DaemonSets is the number of DaemonSets.
+	DaemonSets *int64 `json:"daemonSets,omitempty" protobuf:"varint,5,opt,name=daemonSets"`
+	// Jobs is the number of Jobs.
+	Jobs *int64 `json:"jobs,omitempty" protobuf:"varint,6,opt,name=jobs"`
+	// CronJobs is the number of CronJobs (ScheduledJobs in older versions).
+	CronJobs *int64 `json:"cronJobs,omitempty" protobuf:"varint,7,opt,name=cronJobs"`
+	// Ingresses is the number of Ingresses.
+	Ingresses *int64 `json:"ingresses,omitempty" protobuf:"varint,8,opt,name=ingresses"`
+	// ResourceQuotas is the number of ResourceQuotas.
+	ResourceQuotas *int64 `json:"resourceQuotas,omitempty" protobuf:"varint,9,opt,name=resourceQuotas"`
+	// LimitRanges is the number of LimitRanges.
+	LimitRanges *int64 `json:"limitRanges,omitempty" protobuf:"varint,10,opt,name=limitRanges"`
+}
+
+// PodSecurity is the number of pods that use each of a few security-relevant
+// settings.  Nothing else about the pods is reported.
+type PodSecurity struct {
+	// PodCount is the number of pods.
+	PodCount int64 `json:"podCount" protobuf:"varint,1,opt,name=podCount"` // required
+	// HostNetwork is the number of pods that use the host's network namespace.
+	HostNetwork int64 `json:"hostNetwork" protobuf:"varint,2,opt,name=hostNetwork"` // required
+	// HostPID is the number of pods that use the host's PID namespace.
+	HostPID int64 `json:"hostPID" protobuf:"varint,3,opt,name=hostPID"` // required
+	// Privileged is the number of pods that have at least one privileged container.
+	Privileged int64 `json:"privileged" protobuf:"varint,4,opt,name=privileged"` // required
+	// RunAsNonRoot is the number of pods that require all of their containers to run as a non-root user.
+	RunAsNonRoot int64 `json:"runAsNonRoot" protobuf:"varint,5,opt,name=runAsNonRoot"` // required
+	// HostPathVolumes is the number of pods that mount at least one hostPath volume.
+	HostPathVolumes int64 `json:"hostPathVolumes" protobuf:"varint,6,opt,name=hostPathVolumes"` // required
+	// AddedCapabilities is the number of pods that add capabilities to at least one container.
+	AddedCapabilities int64 `json:"addedCapabilities" protobuf:"varint,7,opt,name=addedCapabilities"` // required
+}
+
+type Networking struct {
+	// ServiceCount is the number of services.
+	ServiceCount int64 `json:"serviceCount" protobuf:"varint,1,opt,name=serviceCount"` // required
+	// ServiceTypes is a list of service types, and how many services have
+	// each of them.  The types are "ClusterIP", "NodePort", "LoadBalancer",
+	// "ExternalName", and "Headless" for ClusterIP services without a cluster
+	// IP.
+	ServiceTypes []ValueCount `json:"serviceTypes,omitempty" protobuf:"bytes,2,rep,name=serviceTypes"`
+	// IPFamily is "IPv4", "IPv6" or "DualStack", depending on the addresses
+	// in the pod CIDRs of the nodes and in the cluster IPs of the services.
+	// It is not set if there are no such addresses.
+	IPFamily *string `json:"ipFamily,omitempty" protobuf:"bytes,3,opt,name=ipFamily"`
+	// KubeProxyMode is the mode of the kube-proxy DaemonSet in kube-system,
+	// from its config file if it has one, or else from its --proxy-mode.
+	// It is "default" if neither sets one, "other" if it is not a well-known
+	// mode, or "unknown" if the config file can not be read.  It is not set
+	// if there is no kube-proxy DaemonSet.
+	KubeProxyMode *string `json:"kubeProxyMode,omitempty" protobuf:"bytes,4,opt,name=kubeProxyMode"`
+	// CNIPlugins is a list of the well-known CNI plugins whose DaemonSets run
+	// in kube-system, such as "calico" or "flannel".
+	CNIPlugins []string `json:"cniPlugins,omitempty" protobuf:"bytes,5,rep,name=cniPlugins"`
+}
+
+type Images struct {
+	// ContainerCount is the number of containers, including init
+	// containers, in pods.  Each runs one image.
+	ContainerCount int64 `json:"containerCount" protobuf:"varint,1,opt,name=containerCount"` // required
+	// Registries is a list of registries, and how many containers run an
+	// image from each of them.  Well-known public registries, such as
+	// "docker.io", "gcr.io" or "quay.io", are reported by name; all others
+	// are counted together as "private".
+	Registries []ValueCount `json:"registries,omitempty" protobuf:"bytes,2,rep,name=registries"`
+	// DigestCount is the number of containers whose image is pinned by
+	// digest.
+	DigestCount int64 `json:"digestCount" protobuf:"varint,3,opt,name=digestCount"` // required
+	// TagCount is the number of containers whose image has a tag but no
+	// digest.
+	TagCount int64 `json:"tagCount" protobuf:"varint,4,opt,name=tagCount"` // required
+	// UntaggedCount is the number of containers whose image has neither a
+	// tag nor a digest, and so implicitly uses "latest".
+	UntaggedCount int64 `json:"untaggedCount" protobuf:"varint,5,opt,name=untaggedCount"` // required
+	// DigestFraction is DigestCount divided by ContainerCount, or 0 if there
+	// are no containers.
+	DigestFraction float64 `json:"digestFraction" protobuf:"fixed64,6,opt,name=digestFraction"` // required
+}
+
+type KubernetesDistribution struct {
+	// RulesVersion is the version of the volunteer's rules for detecting
+	// distributions.  A distribution that has no rule can never be detected.
+	RulesVersion string `json:"rulesVersion" protobuf:"bytes,1,opt,name=rulesVersion"` // required
+	// Name is the identifier of the detected distribution, e.g. "gke", or
+	// "unknown" if no rule matched.
+	Name string `json:"name" protobuf:"bytes,2,opt,name=name"` // required
+	// Confidence is how sure the detection is: "high", "medium" or "low", or
+	// "none" if no rule matched.
+	Confidence string `json:"confidence" protobuf:"bytes,3,opt,name=confidence"` // required
+	// Signals is a list of the kinds of signals that matched the rule:
+	// "version", "nodeLabel", "providerID" or "namespace".
+	Signals []string `json:"signals,omitempty" protobuf:"bytes,4,rep,name=signals"`
 }
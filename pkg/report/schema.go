@@ -0,0 +1,173 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/version"
+)
+
+// FieldType is the type of a Field in the report schema.
+type FieldType string
+
+const (
+	StringField  FieldType = "string"
+	IntegerField FieldType = "integer"
+	FloatField   FieldType = "number"
+	BooleanField FieldType = "boolean"
+	RecordField  FieldType = "record"
+)
+
+// Field describes one field of the report schema.  The schema is derived from
+// the report types, so that every consumer (JSON Schema, BigQuery, ...) sees
+// the same thing.
+type Field struct {
+	// Name is the JSON name of the field.
+	Name string
+	// Type is the type of the field, or of each element if Repeated.
+	Type FieldType
+	// Required is true if the field must always be present.
+	Required bool
+	// Server is true if the field is set by the collector, which ignores
+	// any value sent by clients.  Clients are not required to send it, even
+	// if it is Required of stored records.  It is marked with a
+	// `schema:"server"` tag.
+	Server bool
+	// Repeated is true if the field is a list.
+	Repeated bool
+	// Record is the name of the Go type of a RecordField.
+	Record string
+	// Fields are the fields of a RecordField.
+	Fields []Field
+}
+
+// Fields returns the schema of a Record.
+func Fields() []Field {
+	return fieldsOf(reflect.TypeOf(Record{}))
+}
+
+func fieldsOf(t reflect.Type) []Field {
+	fields := []Field{}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := strings.Split(sf.Tag.Get("json"), ",")
+		if tag[0] == "-" {
+			continue
+		}
+
+		f := Field{Name: tag[0], Server: sf.Tag.Get("schema") == "server"}
+		if f.Name == "" {
+			f.Name = sf.Name
+		}
+		ft := sf.Type
+		switch ft.Kind() {
+		case reflect.Ptr:
+			ft = ft.Elem()
+		case reflect.Slice:
+			f.Repeated = true
+			ft = ft.Elem()
+		default:
+			f.Required = !hasTagOption(tag, "omitempty")
+		}
+
+		switch ft.Kind() {
+		case reflect.String:
+			f.Type = StringField
+		case reflect.Int, reflect.Int32, reflect.Int64:
+			f.Type = IntegerField
+		case reflect.Float32, reflect.Float64:
+			f.Type = FloatField
+		case reflect.Bool:
+			f.Type = BooleanField
+		case reflect.Struct:
+			f.Type = RecordField
+			f.Record = ft.Name()
+			f.Fields = fieldsOf(ft)
+		default:
+			// This can only be hit by changing the report types.
+			panic(fmt.Sprintf("unsupported type for report field %s.%s: %v", t.Name(), sf.Name, ft))
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+func hasTagOption(tag []string, opt string) bool {
+	for _, o := range tag[1:] {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// JSONSchema is a (draft 4) JSON Schema document.
+type JSONSchema struct {
+	Schema      string                 `json:"$schema,omitempty"`
+	Ref         string                 `json:"$ref,omitempty"`
+	Title       string                 `json:"title,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Type        string                 `json:"type,omitempty"`
+	Properties  map[string]*JSONSchema `json:"properties,omitempty"`
+	Required    []string               `json:"required,omitempty"`
+	Items       *JSONSchema            `json:"items,omitempty"`
+	Definitions map[string]*JSONSchema `json:"definitions,omitempty"`
+}
+
+// GenerateJSONSchema returns a JSON Schema describing a Record.  Nested types
+// are described under "definitions" by their Go type name.
+func GenerateJSONSchema() *JSONSchema {
+	defs := map[string]*JSONSchema{}
+	root := objectSchema(Fields(), defs)
+	root.Schema = "http://json-schema.org/draft-04/schema#"
+	root.Title = "Record"
+	root.Description = fmt.Sprintf("Spartakus report, version %s", version.VERSION)
+	root.Definitions = defs
+	return root
+}
+
+func objectSchema(fields []Field, defs map[string]*JSONSchema) *JSONSchema {
+	s := &JSONSchema{
+		Type:       "object",
+		Properties: map[string]*JSONSchema{},
+	}
+	for _, f := range fields {
+		var p *JSONSchema
+		if f.Type == RecordField {
+			if defs[f.Record] == nil {
+				defs[f.Record] = objectSchema(f.Fields, defs)
+			}
+			p = &JSONSchema{Ref: "#/definitions/" + f.Record}
+		} else {
+			p = &JSONSchema{Type: string(f.Type)}
+		}
+		if f.Repeated {
+			p = &JSONSchema{Type: "array", Items: p}
+		}
+		if f.Server {
+			p.Description = "Set by the collector; values sent by clients are ignored."
+		}
+		s.Properties[f.Name] = p
+		if f.Required && !f.Server {
+			s.Required = append(s.Required, f.Name)
+		}
+	}
+	return s
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"testing"
+)
+
+func findField(fields []Field, name string) *Field {
+	for i := range fields {
+		if fields[i].Name == name {
+			return &fields[i]
+		}
+	}
+	return nil
+}
+
+func TestFields(t *testing.T) {
+	testCases := []struct {
+		path     []string
+		typ      FieldType
+		required bool
+		repeated bool
+		server   bool
+	}{
+		{path: []string{"version"}, typ: StringField, required: true},
+		{path: []string{"timestamp"}, typ: StringField, required: true, server: true},
+		{path: []string{"clusterID"}, typ: StringField, required: true},
+		{path: []string{"masterVersion"}, typ: StringField},
+		{path: []string{"nodes"}, typ: RecordField, repeated: true},
+		{path: []string{"nodes", "id"}, typ: StringField, required: true},
+		{path: []string{"nodes", "kubeletVersion"}, typ: StringField},
+		{path: []string{"nodes", "capacity"}, typ: RecordField, repeated: true},
+		{path: []string{"nodes", "capacity", "value"}, typ: StringField, required: true},
+		{path: []string{"extensions", "name"}, typ: StringField, required: true},
+	}
+
+	for i, tc := range testCases {
+		fields := Fields()
+		var f *Field
+		for _, name := range tc.path {
+			f = findField(fields, name)
+			if f == nil {
+				break
+			}
+			fields = f.Fields
+		}
+		if f == nil {
+			t.Errorf("[%d] field %v not found", i, tc.path)
+			continue
+		}
+		if f.Type != tc.typ || f.Required != tc.required || f.Repeated != tc.repeated || f.Server != tc.server {
+			t.Errorf("[%d] field %v: expected %s/%v/%v/%v, got %s/%v/%v/%v",
+				i, tc.path, tc.typ, tc.required, tc.repeated, tc.server, f.Type, f.Required, f.Repeated, f.Server)
+		}
+	}
+}
+
+func TestGenerateJSONSchema(t *testing.T) {
+	s := GenerateJSONSchema()
+
+	if s.Properties["nodes"] == nil || s.Properties["nodes"].Type != "array" {
+		t.Fatalf("expected nodes to be an array, got %+v", s.Properties["nodes"])
+	}
+	if ref := s.Properties["nodes"].Items.Ref; ref != "#/definitions/Node" {
+		t.Errorf("expected nodes to refer to Node, got %q", ref)
+	}
+	// The collector sets the timestamp, so clients need not send it.
+	for _, name := range s.Required {
+		if name == "timestamp" {
+			t.Errorf("expected timestamp not to be required, got %v", s.Required)
+		}
+	}
+	if s.Properties["timestamp"] == nil || s.Properties["timestamp"].Description == "" {
+		t.Errorf("expected timestamp to be described as set by the collector, got %+v", s.Properties["timestamp"])
+	}
+	node := s.Definitions["Node"]
+	if node == nil {
+		t.Fatalf("expected a definition for Node")
+	}
+	if len(node.Required) != 1 || node.Required[0] != "id" {
+		t.Errorf("expected Node to require only id, got %v", node.Required)
+	}
+	if s.Definitions["Resource"] == nil || s.Definitions["Extension"] == nil {
+		t.Errorf("expected definitions for Resource and Extension, got %v", s.Definitions)
+	}
+}
@@ -0,0 +1,305 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+	kresource "k8s.io/client-go/1.5/pkg/api/resource"
+)
+
+// ConvertFromV1 converts a version 1 record into a version 2 record.  The
+// conversion is lossless: ConvertToV1 will return a record equal to in.  Any
+// value that can not be represented exactly in version 2 is an error.
+func ConvertFromV1(in report.Record) (Record, error) {
+	out, errs := ConvertFromV1Partial(in)
+	if len(errs) > 0 {
+		return Record{}, errs[0]
+	}
+	return out, nil
+}
+
+// ConvertFromV1Partial converts a version 1 record into a version 2 record
+// like ConvertFromV1, but leaves out the values that can not be represented
+// exactly in version 2, such as a kubelet version that is not a semantic
+// version, instead of failing.  It returns an error for each value that was
+// left out.
+func ConvertFromV1Partial(in report.Record) (Record, []error) {
+	var errs []error
+	out := Record{
+		Version:              in.Version,
+		ClusterID:            in.ClusterID,
+		Extensions:           in.Extensions,
+		ExtensionDiagnostics: in.ExtensionDiagnostics,
+		Namespaces:           in.Namespaces,
+		Lifetimes:            in.Lifetimes,
+		APIGroups:            in.APIGroups,
+		Ecosystem:            in.Ecosystem,
+		Topology:             in.Topology,
+		Summary:              in.Summary,
+		Storage:              in.Storage,
+		WorkloadFeatures:     in.WorkloadFeatures,
+		PodSecurity:          in.PodSecurity,
+		Networking:           in.Networking,
+		Images:               in.Images,
+		Distribution:         in.Distribution,
+	}
+
+	if in.Timestamp != "" {
+		secs, err := strconv.ParseInt(in.Timestamp, 10, 64)
+		if err != nil || strconv.FormatInt(secs, 10) != in.Timestamp {
+			errs = append(errs, fmt.Errorf("timestamp: invalid UNIX timestamp %q", in.Timestamp))
+		} else {
+			out.Timestamp = time.Unix(secs, 0).UTC()
+		}
+	}
+
+	if in.MasterVersion != nil {
+		if v, err := ParseVersion(*in.MasterVersion); err != nil {
+			errs = append(errs, fmt.Errorf("masterVersion: %v", err))
+		} else {
+			out.MasterVersion = &v
+		}
+	}
+
+	for i := range in.Nodes {
+		n, nodeErrs := convertNodeFromV1(in.Nodes[i])
+		for _, err := range nodeErrs {
+			errs = append(errs, fmt.Errorf("nodes[%d].%v", i, err))
+		}
+		out.Nodes = append(out.Nodes, n)
+	}
+
+	for i := range in.NodeGroups {
+		g, groupErrs := convertNodeGroupFromV1(in.NodeGroups[i])
+		for _, err := range groupErrs {
+			errs = append(errs, fmt.Errorf("nodeGroups[%d].%v", i, err))
+		}
+		out.NodeGroups = append(out.NodeGroups, g)
+	}
+
+	return out, errs
+}
+
+// convertNodeFromV1 converts a node, leaving out the values that can not be
+// converted, with an error for each.
+func convertNodeFromV1(in report.Node) (Node, []error) {
+	out := Node{
+		ID:                      in.ID,
+		OperatingSystem:         in.OperatingSystem,
+		OSImage:                 in.OSImage,
+		KernelVersion:           in.KernelVersion,
+		Architecture:            in.Architecture,
+		ContainerRuntimeVersion: in.ContainerRuntimeVersion,
+		CloudProvider:           in.CloudProvider,
+		Conditions:              in.Conditions,
+		Unschedulable:           in.Unschedulable,
+		Taints:                  in.Taints,
+		Region:                  in.Region,
+		Zone:                    in.Zone,
+		InstanceType:            in.InstanceType,
+		Roles:                   in.Roles,
+		ContainerRuntimeName:    in.ContainerRuntimeName,
+		ContainerRuntimeSemver:  in.ContainerRuntimeSemver,
+		KubeletSemver:           in.KubeletSemver,
+	}
+
+	var errs []error
+	if in.KubeletVersion != nil {
+		if v, err := ParseVersion(*in.KubeletVersion); err != nil {
+			errs = append(errs, fmt.Errorf("kubeletVersion: %v", err))
+		} else {
+			out.KubeletVersion = &v
+		}
+	}
+
+	var resourceErrs []error
+	out.Capacity, resourceErrs = convertResourcesFromV1(in.Capacity)
+	for _, err := range resourceErrs {
+		errs = append(errs, fmt.Errorf("capacity%v", err))
+	}
+	out.Allocatable, resourceErrs = convertResourcesFromV1(in.Allocatable)
+	for _, err := range resourceErrs {
+		errs = append(errs, fmt.Errorf("allocatable%v", err))
+	}
+
+	return out, errs
+}
+
+// convertNodeGroupFromV1 converts a node group, leaving out the values that
+// can not be converted, with an error for each.
+func convertNodeGroupFromV1(in report.NodeGroup) (NodeGroup, []error) {
+	out := NodeGroup{
+		Count:                   in.Count,
+		OperatingSystem:         in.OperatingSystem,
+		OSImage:                 in.OSImage,
+		KernelVersion:           in.KernelVersion,
+		Architecture:            in.Architecture,
+		ContainerRuntimeVersion: in.ContainerRuntimeVersion,
+		CloudProvider:           in.CloudProvider,
+	}
+
+	var errs []error
+	if in.KubeletVersion != nil {
+		if v, err := ParseVersion(*in.KubeletVersion); err != nil {
+			errs = append(errs, fmt.Errorf("kubeletVersion: %v", err))
+		} else {
+			out.KubeletVersion = &v
+		}
+	}
+
+	var resourceErrs []error
+	out.Capacity, resourceErrs = convertResourcesFromV1(in.Capacity)
+	for _, err := range resourceErrs {
+		errs = append(errs, fmt.Errorf("capacity%v", err))
+	}
+
+	return out, errs
+}
+
+// convertResourcesFromV1 converts a list of resources, leaving out those
+// that can not be converted.  Errors start with the index of the offending
+// resource, e.g. "[0].value: ...".
+func convertResourcesFromV1(in []report.Resource) ([]Resource, []error) {
+	var out []Resource
+	var errs []error
+	for i, res := range in {
+		q, err := kresource.ParseQuantity(res.Value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("[%d].value: %v", i, err))
+			continue
+		}
+		// Quantities are always written in canonical form, so anything else
+		// would not survive a round trip.
+		if q.String() != res.Value {
+			errs = append(errs, fmt.Errorf("[%d].value: %q is not in canonical form (%q)", i, res.Value, q.String()))
+			continue
+		}
+		out = append(out, Resource{
+			Resource: res.Resource,
+			Quantity: q,
+		})
+	}
+	return out, errs
+}
+
+// ConvertToV1 converts a version 2 record into a version 1 record.  The
+// timestamp is truncated to the second.
+func ConvertToV1(in Record) report.Record {
+	out := report.Record{
+		Version:              in.Version,
+		ClusterID:            in.ClusterID,
+		Extensions:           in.Extensions,
+		ExtensionDiagnostics: in.ExtensionDiagnostics,
+		Namespaces:           in.Namespaces,
+		Lifetimes:            in.Lifetimes,
+		APIGroups:            in.APIGroups,
+		Ecosystem:            in.Ecosystem,
+		Topology:             in.Topology,
+		Summary:              in.Summary,
+		Storage:              in.Storage,
+		WorkloadFeatures:     in.WorkloadFeatures,
+		PodSecurity:          in.PodSecurity,
+		Networking:           in.Networking,
+		Images:               in.Images,
+		Distribution:         in.Distribution,
+	}
+
+	if !in.Timestamp.IsZero() {
+		out.Timestamp = strconv.FormatInt(in.Timestamp.Unix(), 10)
+	}
+
+	if in.MasterVersion != nil {
+		s := in.MasterVersion.String()
+		out.MasterVersion = &s
+	}
+
+	for i := range in.Nodes {
+		out.Nodes = append(out.Nodes, convertNodeToV1(in.Nodes[i]))
+	}
+
+	for i := range in.NodeGroups {
+		out.NodeGroups = append(out.NodeGroups, convertNodeGroupToV1(in.NodeGroups[i]))
+	}
+
+	return out
+}
+
+func convertNodeToV1(in Node) report.Node {
+	out := report.Node{
+		ID:                      in.ID,
+		OperatingSystem:         in.OperatingSystem,
+		OSImage:                 in.OSImage,
+		KernelVersion:           in.KernelVersion,
+		Architecture:            in.Architecture,
+		ContainerRuntimeVersion: in.ContainerRuntimeVersion,
+		CloudProvider:           in.CloudProvider,
+		Capacity:                convertResourcesToV1(in.Capacity),
+		Allocatable:             convertResourcesToV1(in.Allocatable),
+		Conditions:              in.Conditions,
+		Unschedulable:           in.Unschedulable,
+		Taints:                  in.Taints,
+		Region:                  in.Region,
+		Zone:                    in.Zone,
+		InstanceType:            in.InstanceType,
+		Roles:                   in.Roles,
+		ContainerRuntimeName:    in.ContainerRuntimeName,
+		ContainerRuntimeSemver:  in.ContainerRuntimeSemver,
+		KubeletSemver:           in.KubeletSemver,
+	}
+
+	if in.KubeletVersion != nil {
+		s := in.KubeletVersion.String()
+		out.KubeletVersion = &s
+	}
+
+	return out
+}
+
+func convertNodeGroupToV1(in NodeGroup) report.NodeGroup {
+	out := report.NodeGroup{
+		Count:                   in.Count,
+		OperatingSystem:         in.OperatingSystem,
+		OSImage:                 in.OSImage,
+		KernelVersion:           in.KernelVersion,
+		Architecture:            in.Architecture,
+		ContainerRuntimeVersion: in.ContainerRuntimeVersion,
+		CloudProvider:           in.CloudProvider,
+		Capacity:                convertResourcesToV1(in.Capacity),
+	}
+
+	if in.KubeletVersion != nil {
+		s := in.KubeletVersion.String()
+		out.KubeletVersion = &s
+	}
+
+	return out
+}
+
+func convertResourcesToV1(in []Resource) []report.Resource {
+	var out []report.Resource
+	for _, res := range in {
+		out = append(out, report.Resource{
+			Resource: res.Resource,
+			Value:    res.Quantity.String(),
+		})
+	}
+	return out
+}
@@ -0,0 +1,361 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func strPtr(str string) *string {
+	return &str
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
+
+func TestConvertRoundTrip(t *testing.T) {
+	testCases := []report.Record{
+		{},
+		{
+			Version:   "v1.0.0",
+			ClusterID: "cluster",
+		},
+		{
+			Version:       "v1.0.0",
+			Timestamp:     "1478020000",
+			ClusterID:     "cluster",
+			MasterVersion: strPtr("v1.4.6+e569a27"),
+			Nodes: []report.Node{
+				{
+					ID:                      "node1",
+					OperatingSystem:         strPtr("linux"),
+					OSImage:                 strPtr("Debian GNU/Linux 7 (wheezy)"),
+					KernelVersion:           strPtr("3.16.0-4-amd64"),
+					Architecture:            strPtr("amd64"),
+					ContainerRuntimeVersion: strPtr("docker://1.11.2"),
+					KubeletVersion:          strPtr("v1.5.0-alpha.2.421+a6bea3d79b8bba"),
+					CloudProvider:           strPtr("aws"),
+					Capacity: []report.Resource{
+						{Resource: "cpu", Value: "3500m"},
+						{Resource: "memory", Value: "15437428Ki"},
+						{Resource: "pods", Value: "110"},
+					},
+					Allocatable: []report.Resource{
+						{Resource: "cpu", Value: "3"},
+						{Resource: "memory", Value: "14388852Ki"},
+					},
+					Conditions: []report.NodeCondition{
+						{Type: "Ready", Status: "True"},
+					},
+					Unschedulable: boolPtr(true),
+					Taints: []report.TaintSummary{
+						{Effect: "NoSchedule", Count: 1, KeyHashes: []string{"5d41402abc4b2a76b9719d911017c592"}},
+					},
+					Region:                 strPtr("us-east-1"),
+					Zone:                   strPtr("us-east-1a"),
+					InstanceType:           strPtr("m4.large"),
+					Roles:                  []string{"master"},
+					ContainerRuntimeName:   strPtr("docker"),
+					ContainerRuntimeSemver: &report.Semver{Major: 1, Minor: 11, Patch: 2},
+					KubeletSemver:          &report.Semver{Major: 1, Minor: 5, Prerelease: strPtr("alpha.2.421")},
+				},
+				{ID: "node2"},
+			},
+			Extensions: []report.Extension{
+				{Name: "example.com/hello", Value: "world"},
+			},
+			ExtensionDiagnostics: []report.ExtensionDiagnostic{
+				{Source: "extensions.json", Count: 1},
+			},
+			Namespaces: &report.Namespaces{
+				Count: 1,
+				Histograms: []report.Histogram{
+					{Kind: "pods", Buckets: []report.Bucket{{Min: 1001, Count: 1}}},
+				},
+			},
+			Lifetimes: []report.Lifetime{
+				{
+					Kind: "pods",
+					Live: &report.Distribution{
+						Count:          1,
+						AverageSeconds: 60,
+						Percentiles:    []report.Percentile{{Percentile: 50, Seconds: 60}},
+					},
+				},
+			},
+			APIGroups: []report.APIGroup{
+				{Name: "", Versions: []string{"v1"}, PreferredVersion: strPtr("v1")},
+			},
+			Ecosystem: &report.Ecosystem{
+				CatalogVersion: "1",
+				Projects:       []string{"istio"},
+			},
+			Topology: &report.Topology{
+				RegionCount: 1,
+				ZoneCount:   1,
+				Roles:       []report.RoleCount{{Role: "master", Count: 1}},
+			},
+			Summary: &report.Summary{
+				NodeCount:       2,
+				KubeletVersions: []report.ValueCount{{Value: "v1.5.0-alpha.2.421+a6bea3d79b8bba", Count: 1}},
+			},
+			Images: &report.Images{
+				ContainerCount: 2,
+				Registries:     []report.ValueCount{{Value: "gcr.io", Count: 2}},
+				DigestCount:    1,
+				TagCount:       1,
+				DigestFraction: 0.5,
+			},
+			Distribution: &report.KubernetesDistribution{
+				RulesVersion: "1",
+				Name:         "k3s",
+				Confidence:   "medium",
+				Signals:      []string{"version"},
+			},
+			Networking: &report.Networking{
+				ServiceCount: 1,
+				ServiceTypes: []report.ValueCount{{Value: "LoadBalancer", Count: 1}},
+				IPFamily:     strPtr("DualStack"),
+				CNIPlugins:   []string{"cilium"},
+			},
+			PodSecurity: &report.PodSecurity{
+				PodCount:    12,
+				HostNetwork: 3,
+			},
+			WorkloadFeatures: &report.WorkloadFeatures{
+				Jobs:     int64Ptr(4),
+				CronJobs: int64Ptr(0),
+			},
+			Storage: &report.Storage{
+				ClassCount:   1,
+				Provisioners: []report.ValueCount{{Value: "kubernetes.io/gce-pd", Count: 1}},
+				PersistentVolumeClaims: &report.Volumes{
+					Count:       1,
+					AccessModes: []report.ValueCount{{Value: "ReadWriteOnce", Count: 1}},
+					CapacityGiB: []report.Bucket{{Min: 0, Max: int64Ptr(1), Count: 1}},
+				},
+			},
+		},
+		{
+			Version:   "v1.0.0",
+			ClusterID: "grouped",
+			NodeGroups: []report.NodeGroup{
+				{
+					Count:          3,
+					OSImage:        strPtr("Debian GNU/Linux 7 (wheezy)"),
+					KubeletVersion: strPtr("v1.4.6"),
+					Capacity: []report.Resource{
+						{Resource: "cpu", Value: "4"},
+						{Resource: "memory", Value: "15437428Ki"},
+					},
+				},
+				{Count: 1},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		v2rec, err := ConvertFromV1(tc)
+		if err != nil {
+			t.Errorf("[%d] unexpected error: %v", i, err)
+			continue
+		}
+
+		// Make sure the round trip also survives the wire.
+		j, err := json.Marshal(v2rec)
+		if err != nil {
+			t.Errorf("[%d] failed to encode: %v", i, err)
+			continue
+		}
+		var decoded Record
+		if err := json.Unmarshal(j, &decoded); err != nil {
+			t.Errorf("[%d] failed to decode %s: %v", i, j, err)
+			continue
+		}
+
+		v1rec := ConvertToV1(decoded)
+		if !reflect.DeepEqual(v1rec, tc) {
+			t.Errorf("[%d] did not get expected result:\n%s", i, pretty.Compare(v1rec, tc))
+		}
+	}
+}
+
+func TestConvertFromV1Typed(t *testing.T) {
+	in := report.Record{
+		Timestamp:     "1478020000",
+		MasterVersion: strPtr("v1.4.6"),
+		Nodes: []report.Node{
+			{
+				ID:             "node1",
+				KubeletVersion: strPtr("v1.3.2"),
+				Capacity: []report.Resource{
+					{Resource: "memory", Value: "1Gi"},
+				},
+			},
+		},
+	}
+
+	out, err := ConvertFromV1(in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !out.Timestamp.Equal(time.Unix(1478020000, 0)) {
+		t.Errorf("expected timestamp %v, got %v", time.Unix(1478020000, 0), out.Timestamp)
+	}
+	if out.MasterVersion.Minor != 4 || out.MasterVersion.Patch != 6 {
+		t.Errorf("expected master version 1.4.6, got %v", out.MasterVersion)
+	}
+	if out.Nodes[0].KubeletVersion.Minor != 3 {
+		t.Errorf("expected kubelet version 1.3.2, got %v", out.Nodes[0].KubeletVersion)
+	}
+	if v := out.Nodes[0].Capacity[0].Quantity.Value(); v != 1<<30 {
+		t.Errorf("expected memory capacity %d, got %d", 1<<30, v)
+	}
+}
+
+func TestConvertFromV1Errors(t *testing.T) {
+	testCases := []struct {
+		tweak  func(r *report.Record)
+		errstr string
+	}{
+		{
+			tweak:  func(r *report.Record) { r.Timestamp = "yesterday" },
+			errstr: "timestamp",
+		},
+		{
+			tweak:  func(r *report.Record) { r.Timestamp = "0042" },
+			errstr: "timestamp",
+		},
+		{
+			tweak:  func(r *report.Record) { r.MasterVersion = strPtr("1.4.6") },
+			errstr: "masterVersion",
+		},
+		{
+			tweak:  func(r *report.Record) { r.MasterVersion = strPtr("v1.4") },
+			errstr: "masterVersion",
+		},
+		{
+			tweak:  func(r *report.Record) { r.Nodes[0].KubeletVersion = strPtr("kubelet") },
+			errstr: "nodes[0].kubeletVersion",
+		},
+		{
+			tweak: func(r *report.Record) {
+				r.Nodes[0].Capacity = []report.Resource{{Resource: "cpu", Value: "lots"}}
+			},
+			errstr: "nodes[0].capacity[0].value",
+		},
+		{
+			tweak: func(r *report.Record) {
+				r.Nodes[0].Capacity = []report.Resource{{Resource: "cpu", Value: "1000m"}}
+			},
+			errstr: "canonical",
+		},
+		{
+			tweak: func(r *report.Record) {
+				r.Nodes[0].Allocatable = []report.Resource{{Resource: "cpu", Value: "1"}, {Resource: "cpu", Value: "lots"}}
+			},
+			errstr: "nodes[0].allocatable[1].value",
+		},
+		{
+			tweak: func(r *report.Record) {
+				r.NodeGroups = []report.NodeGroup{{Count: 1, KubeletVersion: strPtr("kubelet")}}
+			},
+			errstr: "nodeGroups[0].kubeletVersion",
+		},
+		{
+			tweak: func(r *report.Record) {
+				r.NodeGroups = []report.NodeGroup{{Count: 1, Capacity: []report.Resource{{Resource: "cpu", Value: "lots"}}}}
+			},
+			errstr: "nodeGroups[0].capacity[0].value",
+		},
+	}
+
+	for i, tc := range testCases {
+		rec := report.Record{Nodes: []report.Node{{ID: "node1"}}}
+		tc.tweak(&rec)
+		_, err := ConvertFromV1(rec)
+		if err == nil {
+			t.Errorf("[%d] expected error containing %q: no error", i, tc.errstr)
+		} else if !strings.Contains(err.Error(), tc.errstr) {
+			t.Errorf("[%d] expected error containing %q, got %q", i, tc.errstr, err)
+		}
+	}
+}
+
+func TestConvertFromV1Partial(t *testing.T) {
+	in := report.Record{
+		Version:       "v1.2.3",
+		ClusterID:     "cluster1",
+		MasterVersion: strPtr("1.4.6-custom"),
+		Nodes: []report.Node{
+			{
+				ID:             "node1",
+				KubeletVersion: strPtr("kubelet"),
+				Capacity: []report.Resource{
+					{Resource: "cpu", Value: "lots"},
+					{Resource: "memory", Value: "1Gi"},
+				},
+			},
+			{
+				ID:             "node2",
+				KubeletVersion: strPtr("v1.4.6"),
+			},
+		},
+	}
+
+	out, errs := ConvertFromV1Partial(in)
+	errstrs := []string{"masterVersion", "nodes[0].kubeletVersion", "nodes[0].capacity[0].value"}
+	if len(errs) != len(errstrs) {
+		t.Fatalf("expected %d errors, got %v", len(errstrs), errs)
+	}
+	for i, errstr := range errstrs {
+		if !strings.Contains(errs[i].Error(), errstr) {
+			t.Errorf("[%d] expected error containing %q, got %q", i, errstr, errs[i])
+		}
+	}
+
+	if out.ClusterID != "cluster1" || out.Version != "v1.2.3" {
+		t.Errorf("expected the valid record fields to be kept, got %q and %q", out.ClusterID, out.Version)
+	}
+	if out.MasterVersion != nil {
+		t.Errorf("expected no master version, got %v", *out.MasterVersion)
+	}
+	if len(out.Nodes) != 2 {
+		t.Fatalf("expected 2 nodes, got %d", len(out.Nodes))
+	}
+	if out.Nodes[0].ID != "node1" || out.Nodes[0].KubeletVersion != nil {
+		t.Errorf("expected node1 without a kubelet version, got %+v", out.Nodes[0])
+	}
+	if len(out.Nodes[0].Capacity) != 1 || out.Nodes[0].Capacity[0].Resource != "memory" {
+		t.Errorf("expected only the memory capacity to be kept, got %+v", out.Nodes[0].Capacity)
+	}
+	if out.Nodes[1].KubeletVersion == nil || out.Nodes[1].KubeletVersion.String() != "v1.4.6" {
+		t.Errorf("expected node2 to keep its kubelet version, got %v", out.Nodes[1].KubeletVersion)
+	}
+}
@@ -0,0 +1,223 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package v2 is version 2 of the report schema.  It carries the same
+// information as package report, but with typed fields where version 1 uses
+// strings.
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blang/semver"
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+	kresource "k8s.io/client-go/1.5/pkg/api/resource"
+)
+
+type Record struct {
+	// Version is the version.VERSION of the schema being reported.
+	Version string `json:"version"` // required
+	// Timestamp is the time when the report was received.
+	Timestamp time.Time `json:"timestamp"` // provided by server, client values are ignored
+	// ClusterID is a string reported by the volunteer.  It could be anything but
+	// a random GUID is strongly recommended. This should be a stable value for
+	// the lifetime of the cluster, or else reports will not be assumed to be
+	// the same cluster.  This must not include personally identifiable
+	// information.
+	ClusterID string `json:"clusterID"` // required
+	// MasterVersion is the version of the kubernetes master in the reporting
+	// cluster.
+	MasterVersion *Version `json:"masterVersion,omitempty"`
+	// Nodes is a list of node-specific information from the reporting cluster.
+	Nodes []Node `json:"nodes,omitempty"`
+	// Extensions is a list of key-value pairs of custom values.
+	Extensions []report.Extension `json:"extensions,omitempty"`
+	// ExtensionDiagnostics is a list of diagnostics, one per source of
+	// extensions that the volunteer read.
+	ExtensionDiagnostics []report.ExtensionDiagnostic `json:"extensionDiagnostics,omitempty"`
+	// Namespaces is information about the namespaces in the reporting
+	// cluster.
+	Namespaces *report.Namespaces `json:"namespaces,omitempty"`
+	// Lifetimes is a list of lifetime statistics, one per kind of object.
+	Lifetimes []report.Lifetime `json:"lifetimes,omitempty"`
+	// APIGroups is a list of the API groups served by the kubernetes master
+	// in the reporting cluster.
+	APIGroups []report.APIGroup `json:"apiGroups,omitempty"`
+	// Ecosystem is the set of well-known add-on projects detected in the
+	// reporting cluster.
+	Ecosystem *report.Ecosystem `json:"ecosystem,omitempty"`
+	// Topology is a summary of how the nodes of the reporting cluster are
+	// spread over regions and zones, and of their roles.
+	Topology *report.Topology `json:"topology,omitempty"`
+	// Summary is an aggregate of the nodes of the reporting cluster.
+	Summary *report.Summary `json:"summary,omitempty"`
+	// NodeGroups is a list of groups of nodes with identical attributes.  See
+	// report.Record for details.
+	NodeGroups []NodeGroup `json:"nodeGroups,omitempty"`
+	// Storage is information about the storage classes and persistent
+	// volumes in the reporting cluster.
+	Storage *report.Storage `json:"storage,omitempty"`
+	// WorkloadFeatures is the number of objects of each feature-bearing kind
+	// in the reporting cluster.
+	WorkloadFeatures *report.WorkloadFeatures `json:"workloadFeatures,omitempty"`
+	// PodSecurity is the number of pods that use security-relevant settings
+	// in the reporting cluster.
+	PodSecurity *report.PodSecurity `json:"podSecurity,omitempty"`
+	// Networking is information about the services and the network setup of
+	// the reporting cluster.
+	Networking *report.Networking `json:"networking,omitempty"`
+	// Images is information about where the container images of the pods in
+	// the reporting cluster come from.
+	Images *report.Images `json:"images,omitempty"`
+	// Distribution is the kubernetes distribution that the reporting cluster
+	// was detected to run.
+	Distribution *report.KubernetesDistribution `json:"distribution,omitempty"`
+}
+
+type Node struct {
+	// ID is a unique string that identifies a node in this cluster.  See
+	// report.Node for details.
+	ID string `json:"id"` // required
+	// OperatingSystem is the value reported by kubernetes in the node status.
+	OperatingSystem *string `json:"operatingSystem,omitempty"`
+	// OSImage is the value reported by kubernetes in the node status.
+	OSImage *string `json:"osImage,omitempty"`
+	// KernelVersion is the value reported by kubernetes in the node status.
+	KernelVersion *string `json:"kernelVersion,omitempty"`
+	// Architecture is the value reported by kubernetes in the node status.
+	Architecture *string `json:"architecture,omitempty"`
+	// ContainerRuntimeVersion is the value reported by kubernetes in the node
+	// status.
+	ContainerRuntimeVersion *string `json:"containerRuntimeVersion,omitempty"`
+	// KubeletVersion is the version reported by kubernetes in the node status.
+	KubeletVersion *Version `json:"kubeletVersion,omitempty"`
+	// CloudProvider is the cloud provider of the node, detected from the
+	// <ProviderName> portion of the ProviderID reported by kubernetes in the
+	// node spec, or from well-known labels and annotations.  Unknown
+	// providers are hashed.
+	CloudProvider *string `json:"cloudProvider,omitempty"`
+	// Capacity is a list of resources and their associated quantities as
+	// reported by kubernetes in the node status.
+	Capacity []Resource `json:"capacity,omitempty"`
+	// Allocatable is a list of resources and their associated quantities that
+	// are available for scheduling, as reported by kubernetes in the node
+	// status.
+	Allocatable []Resource `json:"allocatable,omitempty"`
+	// Conditions is a list of the node's current conditions, as reported by
+	// kubernetes in the node status.
+	Conditions []report.NodeCondition `json:"conditions,omitempty"`
+	// Unschedulable is the value reported by kubernetes in the node spec.
+	Unschedulable *bool `json:"unschedulable,omitempty"`
+	// Taints is a summary of the node's taints, one entry per effect.
+	Taints []report.TaintSummary `json:"taints,omitempty"`
+	// Region is the node's region label.  See report.Node for details.
+	Region *string `json:"region,omitempty"`
+	// Zone is the node's zone label.  See report.Node for details.
+	Zone *string `json:"zone,omitempty"`
+	// InstanceType is the node's instance type label.  See report.Node for
+	// details.
+	InstanceType *string `json:"instanceType,omitempty"`
+	// Roles is a list of the node's roles, from its role labels.
+	Roles []string `json:"roles,omitempty"`
+	// ContainerRuntimeName is the <RuntimeName> portion of
+	// ContainerRuntimeVersion.
+	ContainerRuntimeName *string `json:"containerRuntimeName,omitempty"`
+	// ContainerRuntimeSemver is the <Version> portion of
+	// ContainerRuntimeVersion, parsed as a semantic version.
+	ContainerRuntimeSemver *report.Semver `json:"containerRuntimeSemver,omitempty"`
+	// KubeletSemver is KubeletVersion parsed as a semantic version.
+	KubeletSemver *report.Semver `json:"kubeletSemver,omitempty"`
+}
+
+type NodeGroup struct {
+	// Count is the number of nodes in the group.
+	Count int64 `json:"count"` // required
+	// OperatingSystem is the value reported by kubernetes in the status of
+	// the nodes.
+	OperatingSystem *string `json:"operatingSystem,omitempty"`
+	// OSImage is the value reported by kubernetes in the status of the nodes.
+	OSImage *string `json:"osImage,omitempty"`
+	// KernelVersion is the value reported by kubernetes in the status of the
+	// nodes.
+	KernelVersion *string `json:"kernelVersion,omitempty"`
+	// Architecture is the value reported by kubernetes in the status of the
+	// nodes.
+	Architecture *string `json:"architecture,omitempty"`
+	// ContainerRuntimeVersion is the value reported by kubernetes in the
+	// status of the nodes.
+	ContainerRuntimeVersion *string `json:"containerRuntimeVersion,omitempty"`
+	// KubeletVersion is the version reported by kubernetes in the status of
+	// the nodes.
+	KubeletVersion *Version `json:"kubeletVersion,omitempty"`
+	// CloudProvider is the cloud provider of the nodes, detected from the
+	// <ProviderName> portion of the ProviderID reported by kubernetes in the
+	// spec of the nodes, or from well-known labels and annotations.  Unknown
+	// providers are hashed.
+	CloudProvider *string `json:"cloudProvider,omitempty"`
+	// Capacity is a list of resources and their associated quantities as
+	// reported by kubernetes in the status of the nodes.
+	Capacity []Resource `json:"capacity,omitempty"`
+}
+
+type Resource struct {
+	// Resource is the name of the resource.
+	Resource string `json:"resource"` // required
+	// Quantity is the resource's value.
+	Quantity kresource.Quantity `json:"quantity"` // required
+}
+
+// Version is a kubernetes version string, such as "v1.4.6+e569a27", parsed as
+// a semantic version.
+type Version struct {
+	semver.Version
+}
+
+// ParseVersion parses a kubernetes version string.  Kubernetes versions are
+// semantic versions with a leading "v".
+func ParseVersion(s string) (Version, error) {
+	if !strings.HasPrefix(s, "v") {
+		return Version{}, fmt.Errorf("invalid version %q: must start with \"v\"", s)
+	}
+	sv, err := semver.Parse(s[1:])
+	if err != nil {
+		return Version{}, fmt.Errorf("invalid version %q: %v", s, err)
+	}
+	return Version{sv}, nil
+}
+
+func (v Version) String() string {
+	return "v" + v.Version.String()
+}
+
+func (v Version) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.String())
+}
+
+func (v *Version) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	parsed, err := ParseVersion(s)
+	if err != nil {
+		return err
+	}
+	*v = parsed
+	return nil
+}
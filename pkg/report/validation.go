@@ -0,0 +1,678 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FieldError describes a single invalid field in a Record.
+type FieldError struct {
+	// Field is the path to the invalid field, using the JSON field names,
+	// e.g. "nodes[2].capacity[0].resource".
+	Field string `json:"field"`
+	// Detail is a human-readable description of what is wrong.
+	Detail string `json:"detail"`
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Detail)
+}
+
+// Validate checks a Record against the rules documented on its fields and
+// returns one FieldError for each problem found.  A nil result means the
+// Record is valid.
+func (r Record) Validate() []FieldError {
+	var errs []FieldError
+
+	if r.Version == "" {
+		errs = append(errs, FieldError{"version", "required"})
+	}
+	if r.Timestamp != "" {
+		if _, err := strconv.ParseInt(r.Timestamp, 10, 64); err != nil {
+			errs = append(errs, FieldError{"timestamp", "must be a UNIX timestamp"})
+		}
+	}
+	if r.ClusterID == "" {
+		errs = append(errs, FieldError{"clusterID", "required"})
+	}
+
+	ids := map[string]bool{}
+	for i, n := range r.Nodes {
+		path := fmt.Sprintf("nodes[%d]", i)
+		errs = append(errs, n.validate(path)...)
+		if n.ID == "" {
+			continue
+		}
+		if ids[n.ID] {
+			errs = append(errs, FieldError{path + ".id", fmt.Sprintf("duplicate value %q", n.ID)})
+		}
+		ids[n.ID] = true
+	}
+
+	for i, g := range r.NodeGroups {
+		errs = append(errs, g.validate(fmt.Sprintf("nodeGroups[%d]", i))...)
+	}
+
+	for i, e := range r.Extensions {
+		errs = append(errs, e.validate(fmt.Sprintf("extensions[%d]", i))...)
+	}
+
+	for i, d := range r.ExtensionDiagnostics {
+		errs = append(errs, d.validate(fmt.Sprintf("extensionDiagnostics[%d]", i))...)
+	}
+
+	if r.Namespaces != nil {
+		errs = append(errs, r.Namespaces.validate("namespaces")...)
+	}
+
+	kinds := map[string]bool{}
+	for i, l := range r.Lifetimes {
+		path := fmt.Sprintf("lifetimes[%d]", i)
+		errs = append(errs, l.validate(path)...)
+		if l.Kind == "" {
+			continue
+		}
+		if kinds[l.Kind] {
+			errs = append(errs, FieldError{path + ".kind", fmt.Sprintf("duplicate value %q", l.Kind)})
+		}
+		kinds[l.Kind] = true
+	}
+
+	groups := map[string]bool{}
+	for i, g := range r.APIGroups {
+		path := fmt.Sprintf("apiGroups[%d]", i)
+		errs = append(errs, g.validate(path)...)
+		if groups[g.Name] {
+			errs = append(errs, FieldError{path + ".name", fmt.Sprintf("duplicate value %q", g.Name)})
+		}
+		groups[g.Name] = true
+	}
+
+	if r.Ecosystem != nil {
+		errs = append(errs, r.Ecosystem.validate("ecosystem")...)
+	}
+
+	if r.Topology != nil {
+		errs = append(errs, r.Topology.validate("topology")...)
+	}
+
+	if r.Summary != nil {
+		errs = append(errs, r.Summary.validate("summary")...)
+	}
+
+	if r.Storage != nil {
+		errs = append(errs, r.Storage.validate("storage")...)
+	}
+
+	if r.WorkloadFeatures != nil {
+		errs = append(errs, r.WorkloadFeatures.validate("workloadFeatures")...)
+	}
+
+	if r.PodSecurity != nil {
+		errs = append(errs, r.PodSecurity.validate("podSecurity")...)
+	}
+
+	if r.Networking != nil {
+		errs = append(errs, r.Networking.validate("networking")...)
+	}
+
+	if r.Images != nil {
+		errs = append(errs, r.Images.validate("images")...)
+	}
+
+	if r.Distribution != nil {
+		errs = append(errs, r.Distribution.validate("distribution")...)
+	}
+
+	return errs
+}
+
+func (g NodeGroup) validate(path string) []FieldError {
+	var errs []FieldError
+
+	if g.Count <= 0 {
+		errs = append(errs, FieldError{path + ".count", "must be positive"})
+	}
+	for i, res := range g.Capacity {
+		errs = append(errs, res.validate(fmt.Sprintf("%s.capacity[%d]", path, i))...)
+	}
+
+	return errs
+}
+
+func (n Node) validate(path string) []FieldError {
+	var errs []FieldError
+
+	if n.ID == "" {
+		errs = append(errs, FieldError{path + ".id", "required"})
+	}
+	for i, res := range n.Capacity {
+		errs = append(errs, res.validate(fmt.Sprintf("%s.capacity[%d]", path, i))...)
+	}
+	for i, res := range n.Allocatable {
+		errs = append(errs, res.validate(fmt.Sprintf("%s.allocatable[%d]", path, i))...)
+	}
+	types := map[string]bool{}
+	for i, c := range n.Conditions {
+		cpath := fmt.Sprintf("%s.conditions[%d]", path, i)
+		if c.Type == "" {
+			errs = append(errs, FieldError{cpath + ".type", "required"})
+		} else if types[c.Type] {
+			errs = append(errs, FieldError{cpath + ".type", fmt.Sprintf("duplicate value %q", c.Type)})
+		}
+		types[c.Type] = true
+		if c.Status == "" {
+			errs = append(errs, FieldError{cpath + ".status", "required"})
+		}
+	}
+	effects := map[string]bool{}
+	for i, t := range n.Taints {
+		tpath := fmt.Sprintf("%s.taints[%d]", path, i)
+		if t.Effect == "" {
+			errs = append(errs, FieldError{tpath + ".effect", "required"})
+		} else if effects[t.Effect] {
+			errs = append(errs, FieldError{tpath + ".effect", fmt.Sprintf("duplicate value %q", t.Effect)})
+		}
+		effects[t.Effect] = true
+		if t.Count < 0 {
+			errs = append(errs, FieldError{tpath + ".count", "must not be negative"})
+		}
+	}
+	if n.ContainerRuntimeSemver != nil {
+		errs = append(errs, n.ContainerRuntimeSemver.validate(path+".containerRuntimeSemver")...)
+	}
+	if n.KubeletSemver != nil {
+		errs = append(errs, n.KubeletSemver.validate(path+".kubeletSemver")...)
+	}
+	roles := map[string]bool{}
+	for i, r := range n.Roles {
+		rpath := fmt.Sprintf("%s.roles[%d]", path, i)
+		if r == "" {
+			errs = append(errs, FieldError{rpath, "required"})
+		} else if roles[r] {
+			errs = append(errs, FieldError{rpath, fmt.Sprintf("duplicate value %q", r)})
+		}
+		roles[r] = true
+	}
+
+	return errs
+}
+
+func (v Semver) validate(path string) []FieldError {
+	var errs []FieldError
+
+	if v.Major < 0 {
+		errs = append(errs, FieldError{path + ".major", "must not be negative"})
+	}
+	if v.Minor < 0 {
+		errs = append(errs, FieldError{path + ".minor", "must not be negative"})
+	}
+	if v.Patch < 0 {
+		errs = append(errs, FieldError{path + ".patch", "must not be negative"})
+	}
+	if v.Prerelease != nil && *v.Prerelease == "" {
+		errs = append(errs, FieldError{path + ".prerelease", "must be non-empty if set"})
+	}
+
+	return errs
+}
+
+func (res Resource) validate(path string) []FieldError {
+	var errs []FieldError
+
+	if res.Resource == "" {
+		errs = append(errs, FieldError{path + ".resource", "required"})
+	}
+	if res.Value == "" {
+		errs = append(errs, FieldError{path + ".value", "required"})
+	}
+
+	return errs
+}
+
+func (e Extension) validate(path string) []FieldError {
+	var errs []FieldError
+
+	for _, msg := range ValidateExtensionName(e.Name) {
+		errs = append(errs, FieldError{path + ".name", msg})
+	}
+	// Values are not restricted in terms of structure or content, but a
+	// typed value must match the type.
+	typed := map[string]bool{
+		ExtensionTypeInt:   e.IntValue != nil,
+		ExtensionTypeFloat: e.FloatValue != nil,
+		ExtensionTypeBool:  e.BoolValue != nil,
+	}
+	switch e.Type {
+	case "", ExtensionTypeString, ExtensionTypeInt, ExtensionTypeFloat, ExtensionTypeBool:
+	default:
+		errs = append(errs, FieldError{path + ".type", fmt.Sprintf("unknown value %q", e.Type)})
+	}
+	for _, t := range []string{ExtensionTypeInt, ExtensionTypeFloat, ExtensionTypeBool} {
+		if t == e.Type && !typed[t] {
+			errs = append(errs, FieldError{path + "." + t + "Value", "required"})
+		} else if t != e.Type && typed[t] {
+			errs = append(errs, FieldError{path + "." + t + "Value", fmt.Sprintf("must not be set for type %q", e.Type)})
+		}
+	}
+
+	return errs
+}
+
+func (d ExtensionDiagnostic) validate(path string) []FieldError {
+	var errs []FieldError
+
+	if d.Source == "" {
+		errs = append(errs, FieldError{path + ".source", "required"})
+	}
+	if d.Count < 0 {
+		errs = append(errs, FieldError{path + ".count", "must not be negative"})
+	}
+	for i, e := range d.Errors {
+		if e == "" {
+			errs = append(errs, FieldError{fmt.Sprintf("%s.errors[%d]", path, i), "required"})
+		}
+	}
+
+	return errs
+}
+
+func (ns Namespaces) validate(path string) []FieldError {
+	var errs []FieldError
+
+	kinds := map[string]bool{}
+	for i, h := range ns.Histograms {
+		hpath := fmt.Sprintf("%s.histograms[%d]", path, i)
+		if h.Kind == "" {
+			errs = append(errs, FieldError{hpath + ".kind", "required"})
+		} else if kinds[h.Kind] {
+			errs = append(errs, FieldError{hpath + ".kind", fmt.Sprintf("duplicate value %q", h.Kind)})
+		}
+		kinds[h.Kind] = true
+	}
+
+	return errs
+}
+
+func (l Lifetime) validate(path string) []FieldError {
+	var errs []FieldError
+
+	if l.Kind == "" {
+		errs = append(errs, FieldError{path + ".kind", "required"})
+	}
+	if l.Live != nil {
+		errs = append(errs, l.Live.validate(path+".live")...)
+	}
+	if l.Deleted != nil {
+		errs = append(errs, l.Deleted.validate(path+".deleted")...)
+	}
+
+	return errs
+}
+
+func (d Distribution) validate(path string) []FieldError {
+	var errs []FieldError
+
+	for i, p := range d.Percentiles {
+		if p.Percentile < 1 || p.Percentile > 100 {
+			errs = append(errs, FieldError{fmt.Sprintf("%s.percentiles[%d].percentile", path, i), "must be between 1 and 100"})
+		}
+	}
+
+	return errs
+}
+
+func (g APIGroup) validate(path string) []FieldError {
+	var errs []FieldError
+
+	// The name is not required: the core group has none.
+	versions := map[string]bool{}
+	for i, v := range g.Versions {
+		vpath := fmt.Sprintf("%s.versions[%d]", path, i)
+		if v == "" {
+			errs = append(errs, FieldError{vpath, "required"})
+		} else if versions[v] {
+			errs = append(errs, FieldError{vpath, fmt.Sprintf("duplicate value %q", v)})
+		}
+		versions[v] = true
+	}
+	if g.PreferredVersion != nil && !versions[*g.PreferredVersion] {
+		errs = append(errs, FieldError{path + ".preferredVersion", fmt.Sprintf("%q is not one of the versions", *g.PreferredVersion)})
+	}
+
+	return errs
+}
+
+func (e Ecosystem) validate(path string) []FieldError {
+	var errs []FieldError
+
+	if e.CatalogVersion == "" {
+		errs = append(errs, FieldError{path + ".catalogVersion", "required"})
+	}
+	projects := map[string]bool{}
+	for i, p := range e.Projects {
+		ppath := fmt.Sprintf("%s.projects[%d]", path, i)
+		if p == "" {
+			errs = append(errs, FieldError{ppath, "required"})
+		} else if projects[p] {
+			errs = append(errs, FieldError{ppath, fmt.Sprintf("duplicate value %q", p)})
+		}
+		projects[p] = true
+	}
+	if e.UnknownAPIGroups < 0 {
+		errs = append(errs, FieldError{path + ".unknownAPIGroups", "must not be negative"})
+	}
+
+	return errs
+}
+
+func (t Topology) validate(path string) []FieldError {
+	var errs []FieldError
+
+	if t.RegionCount < 0 {
+		errs = append(errs, FieldError{path + ".regionCount", "must not be negative"})
+	}
+	if t.ZoneCount < 0 {
+		errs = append(errs, FieldError{path + ".zoneCount", "must not be negative"})
+	}
+	roles := map[string]bool{}
+	for i, r := range t.Roles {
+		rpath := fmt.Sprintf("%s.roles[%d]", path, i)
+		if r.Role == "" {
+			errs = append(errs, FieldError{rpath + ".role", "required"})
+		} else if roles[r.Role] {
+			errs = append(errs, FieldError{rpath + ".role", fmt.Sprintf("duplicate value %q", r.Role)})
+		}
+		roles[r.Role] = true
+		if r.Count < 0 {
+			errs = append(errs, FieldError{rpath + ".count", "must not be negative"})
+		}
+	}
+
+	return errs
+}
+
+func (s Summary) validate(path string) []FieldError {
+	var errs []FieldError
+
+	if s.NodeCount < 0 {
+		errs = append(errs, FieldError{path + ".nodeCount", "must not be negative"})
+	}
+	if s.CPUCapacityMillis < 0 {
+		errs = append(errs, FieldError{path + ".cpuCapacityMillis", "must not be negative"})
+	}
+	if s.MemoryCapacityBytes < 0 {
+		errs = append(errs, FieldError{path + ".memoryCapacityBytes", "must not be negative"})
+	}
+	if s.PodsCapacity < 0 {
+		errs = append(errs, FieldError{path + ".podsCapacity", "must not be negative"})
+	}
+	errs = append(errs, validateValueCounts(path+".kubeletVersions", s.KubeletVersions)...)
+	errs = append(errs, validateValueCounts(path+".osImages", s.OSImages)...)
+	errs = append(errs, validateValueCounts(path+".architectures", s.Architectures)...)
+
+	return errs
+}
+
+func (s Storage) validate(path string) []FieldError {
+	var errs []FieldError
+
+	if s.ClassCount < 0 {
+		errs = append(errs, FieldError{path + ".classCount", "must not be negative"})
+	}
+	errs = append(errs, validateValueCounts(path+".provisioners", s.Provisioners)...)
+	if s.DefaultClassCount < 0 {
+		errs = append(errs, FieldError{path + ".defaultClassCount", "must not be negative"})
+	}
+	if s.DefaultProvisioner != nil && *s.DefaultProvisioner == "" {
+		errs = append(errs, FieldError{path + ".defaultProvisioner", "must not be empty"})
+	}
+	if s.PersistentVolumes != nil {
+		errs = append(errs, s.PersistentVolumes.validate(path+".persistentVolumes")...)
+	}
+	if s.PersistentVolumeClaims != nil {
+		errs = append(errs, s.PersistentVolumeClaims.validate(path+".persistentVolumeClaims")...)
+	}
+
+	return errs
+}
+
+func (v Volumes) validate(path string) []FieldError {
+	var errs []FieldError
+
+	if v.Count < 0 {
+		errs = append(errs, FieldError{path + ".count", "must not be negative"})
+	}
+	errs = append(errs, validateValueCounts(path+".accessModes", v.AccessModes)...)
+	errs = append(errs, validateValueCounts(path+".reclaimPolicies", v.ReclaimPolicies)...)
+
+	return errs
+}
+
+func (w WorkloadFeatures) validate(path string) []FieldError {
+	var errs []FieldError
+
+	counts := []struct {
+		field string
+		count *int64
+	}{
+		{"horizontalPodAutoscalers", w.HorizontalPodAutoscalers},
+		{"podDisruptionBudgets", w.PodDisruptionBudgets},
+		{"networkPolicies", w.NetworkPolicies},
+		{"statefulSets", w.StatefulSets},
+		{"daemonSets", w.DaemonSets},
+		{"jobs", w.Jobs},
+		{"cronJobs", w.CronJobs},
+		{"ingresses", w.Ingresses},
+		{"resourceQuotas", w.ResourceQuotas},
+		{"limitRanges", w.LimitRanges},
+	}
+	for _, c := range counts {
+		if c.count != nil && *c.count < 0 {
+			errs = append(errs, FieldError{path + "." + c.field, "must not be negative"})
+		}
+	}
+
+	return errs
+}
+
+func (p PodSecurity) validate(path string) []FieldError {
+	var errs []FieldError
+
+	if p.PodCount < 0 {
+		errs = append(errs, FieldError{path + ".podCount", "must not be negative"})
+	}
+	counts := []struct {
+		field string
+		count int64
+	}{
+		{"hostNetwork", p.HostNetwork},
+		{"hostPID", p.HostPID},
+		{"privileged", p.Privileged},
+		{"runAsNonRoot", p.RunAsNonRoot},
+		{"hostPathVolumes", p.HostPathVolumes},
+		{"addedCapabilities", p.AddedCapabilities},
+	}
+	for _, c := range counts {
+		if c.count < 0 {
+			errs = append(errs, FieldError{path + "." + c.field, "must not be negative"})
+		} else if c.count > p.PodCount {
+			errs = append(errs, FieldError{path + "." + c.field, "must not be more than podCount"})
+		}
+	}
+
+	return errs
+}
+
+func (im Images) validate(path string) []FieldError {
+	var errs []FieldError
+
+	if im.ContainerCount < 0 {
+		errs = append(errs, FieldError{path + ".containerCount", "must not be negative"})
+	}
+	errs = append(errs, validateValueCounts(path+".registries", im.Registries)...)
+	if im.DigestCount < 0 {
+		errs = append(errs, FieldError{path + ".digestCount", "must not be negative"})
+	}
+	if im.TagCount < 0 {
+		errs = append(errs, FieldError{path + ".tagCount", "must not be negative"})
+	}
+	if im.UntaggedCount < 0 {
+		errs = append(errs, FieldError{path + ".untaggedCount", "must not be negative"})
+	}
+	if im.DigestFraction < 0 || im.DigestFraction > 1 {
+		errs = append(errs, FieldError{path + ".digestFraction", "must be between 0 and 1"})
+	}
+
+	return errs
+}
+
+// confidences are the valid values of KubernetesDistribution.Confidence.
+var confidences = map[string]bool{
+	"high":   true,
+	"medium": true,
+	"low":    true,
+	"none":   true,
+}
+
+func (d KubernetesDistribution) validate(path string) []FieldError {
+	var errs []FieldError
+
+	if d.RulesVersion == "" {
+		errs = append(errs, FieldError{path + ".rulesVersion", "required"})
+	}
+	if d.Name == "" {
+		errs = append(errs, FieldError{path + ".name", "required"})
+	}
+	if d.Confidence == "" {
+		errs = append(errs, FieldError{path + ".confidence", "required"})
+	} else if !confidences[d.Confidence] {
+		errs = append(errs, FieldError{path + ".confidence", fmt.Sprintf("unknown value %q", d.Confidence)})
+	}
+	signals := map[string]bool{}
+	for i, s := range d.Signals {
+		spath := fmt.Sprintf("%s.signals[%d]", path, i)
+		if s == "" {
+			errs = append(errs, FieldError{spath, "required"})
+		} else if signals[s] {
+			errs = append(errs, FieldError{spath, fmt.Sprintf("duplicate value %q", s)})
+		}
+		signals[s] = true
+	}
+
+	return errs
+}
+
+// ipFamilies are the valid values of Networking.IPFamily.
+var ipFamilies = map[string]bool{
+	"IPv4":      true,
+	"IPv6":      true,
+	"DualStack": true,
+}
+
+func (n Networking) validate(path string) []FieldError {
+	var errs []FieldError
+
+	if n.ServiceCount < 0 {
+		errs = append(errs, FieldError{path + ".serviceCount", "must not be negative"})
+	}
+	errs = append(errs, validateValueCounts(path+".serviceTypes", n.ServiceTypes)...)
+	if n.IPFamily != nil && !ipFamilies[*n.IPFamily] {
+		errs = append(errs, FieldError{path + ".ipFamily", fmt.Sprintf("unknown value %q", *n.IPFamily)})
+	}
+	if n.KubeProxyMode != nil && *n.KubeProxyMode == "" {
+		errs = append(errs, FieldError{path + ".kubeProxyMode", "must not be empty"})
+	}
+	plugins := map[string]bool{}
+	for i, p := range n.CNIPlugins {
+		ppath := fmt.Sprintf("%s.cniPlugins[%d]", path, i)
+		if p == "" {
+			errs = append(errs, FieldError{ppath, "must not be empty"})
+		} else if plugins[p] {
+			errs = append(errs, FieldError{ppath, fmt.Sprintf("duplicate value %q", p)})
+		}
+		plugins[p] = true
+	}
+
+	return errs
+}
+
+func validateValueCounts(path string, vcs []ValueCount) []FieldError {
+	var errs []FieldError
+
+	values := map[string]bool{}
+	for i, vc := range vcs {
+		vpath := fmt.Sprintf("%s[%d]", path, i)
+		if values[vc.Value] {
+			errs = append(errs, FieldError{vpath + ".value", fmt.Sprintf("duplicate value %q", vc.Value)})
+		}
+		values[vc.Value] = true
+		if vc.Count < 0 {
+			errs = append(errs, FieldError{vpath + ".count", "must not be negative"})
+		}
+	}
+
+	return errs
+}
+
+const (
+	dns1123LabelFmt        = "[a-z0-9]([-a-z0-9]*[a-z0-9])?"
+	dns1123SubdomainFmt    = dns1123LabelFmt + "(\\." + dns1123LabelFmt + ")*"
+	dns1123SubdomainMax    = 253
+	extensionNameSeparator = "/"
+)
+
+var dns1123SubdomainRegexp = regexp.MustCompile("^" + dns1123SubdomainFmt + "$")
+
+// ValidateExtensionName checks that name is a valid extension name: an
+// optional prefix and a name, separated by a slash.  The name segment is
+// required.  If specified, the prefix must be a DNS sub-domain.  It returns
+// a description of each problem found.
+func ValidateExtensionName(name string) []string {
+	var msgs []string
+
+	if name == "" {
+		return append(msgs, "required")
+	}
+
+	parts := strings.Split(name, extensionNameSeparator)
+	switch len(parts) {
+	case 1:
+		// No prefix.
+	case 2:
+		prefix := parts[0]
+		if prefix == "" {
+			msgs = append(msgs, "prefix part must be non-empty")
+		} else if len(prefix) > dns1123SubdomainMax {
+			msgs = append(msgs, fmt.Sprintf("prefix part must be no more than %d characters", dns1123SubdomainMax))
+		} else if !dns1123SubdomainRegexp.MatchString(prefix) {
+			msgs = append(msgs, "prefix part must be a DNS sub-domain (e.g. 'example.com')")
+		}
+	default:
+		return append(msgs, "must consist of an optional DNS sub-domain prefix and a name, separated by a '/'")
+	}
+
+	if parts[len(parts)-1] == "" {
+		msgs = append(msgs, "name part must be non-empty")
+	}
+
+	return msgs
+}
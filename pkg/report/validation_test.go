@@ -0,0 +1,475 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"strings"
+	"testing"
+)
+
+func validRecord() Record {
+	return Record{
+		Version:   "v1.0.0",
+		Timestamp: "1478020000",
+		ClusterID: "cluster",
+		Nodes: []Node{
+			{
+				ID: "node1",
+				Capacity: []Resource{
+					{Resource: "cpu", Value: "4"},
+				},
+				Allocatable: []Resource{
+					{Resource: "cpu", Value: "3500m"},
+				},
+				Conditions: []NodeCondition{
+					{Type: "Ready", Status: "True"},
+					{Type: "MemoryPressure", Status: "False"},
+				},
+				Unschedulable: boolPtr(false),
+				Taints: []TaintSummary{
+					{Effect: "NoSchedule", Count: 1, KeyHashes: []string{"5d41402abc4b2a76b9719d911017c592"}},
+				},
+				Region:                 strPtr("us-east-1"),
+				Zone:                   strPtr("us-east-1a"),
+				InstanceType:           strPtr("m4.large"),
+				Roles:                  []string{"master"},
+				ContainerRuntimeName:   strPtr("docker"),
+				ContainerRuntimeSemver: &Semver{Major: 1, Minor: 11, Patch: 2},
+				KubeletSemver:          &Semver{Major: 1, Minor: 5, Patch: 0, Prerelease: strPtr("alpha.2")},
+			},
+			{ID: "node2"},
+		},
+		NodeGroups: []NodeGroup{
+			{
+				Count:        2,
+				Architecture: strPtr("amd64"),
+				Capacity: []Resource{
+					{Resource: "cpu", Value: "4"},
+				},
+			},
+		},
+		Extensions: []Extension{
+			{Name: "example.com/hello", Value: "world"},
+			{Name: "foo", Value: ""},
+			{Name: "example.com/replicas", Value: "3", Type: "int", IntValue: int64Ptr(3)},
+			{Name: "example.com/ratio", Value: "0.5", Type: "float", FloatValue: float64Ptr(0.5)},
+			{Name: "example.com/db.enabled", Value: "true", Type: "bool", BoolValue: boolPtr(true)},
+		},
+		ExtensionDiagnostics: []ExtensionDiagnostic{
+			{Source: "extensions.json", Count: 5},
+			{Source: "broken.json", Errors: []string{`"Example.com/hello": prefix part must be a DNS sub-domain (e.g. 'example.com')`}},
+		},
+		Namespaces: &Namespaces{
+			Count: 3,
+			Histograms: []Histogram{
+				{Kind: "pods", Buckets: []Bucket{{Min: 0, Max: int64Ptr(0), Count: 1}, {Min: 6, Max: int64Ptr(10), Count: 2}}},
+				{Kind: "services", Buckets: []Bucket{{Min: 1, Max: int64Ptr(1), Count: 3}}},
+			},
+		},
+		Lifetimes: []Lifetime{
+			{
+				Kind: "pods",
+				Live: &Distribution{
+					Count:          3,
+					AverageSeconds: 600,
+					Percentiles:    []Percentile{{Percentile: 50, Seconds: 300}, {Percentile: 100, Seconds: 1200}},
+				},
+				Deleted: &Distribution{},
+			},
+			{Kind: "services"},
+		},
+		APIGroups: []APIGroup{
+			{Name: "", Versions: []string{"v1"}, PreferredVersion: strPtr("v1")},
+			{Name: "batch", Versions: []string{"v1", "v2alpha1"}, PreferredVersion: strPtr("v1")},
+		},
+		Ecosystem: &Ecosystem{
+			CatalogVersion:   "1",
+			Projects:         []string{"calico", "istio"},
+			UnknownAPIGroups: 2,
+		},
+		Topology: &Topology{
+			RegionCount: 1,
+			ZoneCount:   1,
+			Roles:       []RoleCount{{Role: "master", Count: 1}},
+		},
+		Storage: &Storage{
+			ClassCount:         2,
+			Provisioners:       []ValueCount{{Value: "kubernetes.io/aws-ebs", Count: 2}},
+			DefaultClassCount:  1,
+			DefaultProvisioner: strPtr("kubernetes.io/aws-ebs"),
+			PersistentVolumes: &Volumes{
+				Count:           3,
+				AccessModes:     []ValueCount{{Value: "ReadWriteOnce", Count: 3}},
+				ReclaimPolicies: []ValueCount{{Value: "Delete", Count: 3}},
+				CapacityGiB:     []Bucket{{Min: 6, Max: int64Ptr(10), Count: 3}},
+			},
+			PersistentVolumeClaims: &Volumes{Count: 0},
+		},
+		WorkloadFeatures: &WorkloadFeatures{
+			HorizontalPodAutoscalers: int64Ptr(2),
+			StatefulSets:             int64Ptr(0),
+		},
+		PodSecurity: &PodSecurity{
+			PodCount:     10,
+			HostNetwork:  2,
+			RunAsNonRoot: 10,
+		},
+		Networking: &Networking{
+			ServiceCount:  3,
+			ServiceTypes:  []ValueCount{{Value: "ClusterIP", Count: 2}, {Value: "Headless", Count: 1}},
+			IPFamily:      strPtr("IPv4"),
+			KubeProxyMode: strPtr("iptables"),
+			CNIPlugins:    []string{"calico"},
+		},
+		Images: &Images{
+			ContainerCount: 4,
+			Registries:     []ValueCount{{Value: "docker.io", Count: 3}, {Value: "private", Count: 1}},
+			DigestCount:    1,
+			TagCount:       2,
+			UntaggedCount:  1,
+			DigestFraction: 0.25,
+		},
+		Distribution: &KubernetesDistribution{
+			RulesVersion: "1",
+			Name:         "gke",
+			Confidence:   "high",
+			Signals:      []string{"version", "nodeLabel"},
+		},
+		Summary: &Summary{
+			NodeCount:           2,
+			CPUCapacityMillis:   4000,
+			MemoryCapacityBytes: 1 << 30,
+			PodsCapacity:        110,
+			KubeletVersions:     []ValueCount{{Value: "v1.4.6", Count: 2}},
+			OSImages:            []ValueCount{{Value: "Debian GNU/Linux 7 (wheezy)", Count: 2}},
+			Architectures:       []ValueCount{{Value: "amd64", Count: 1}, {Value: "arm64", Count: 1}},
+		},
+	}
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
+
+func strPtr(str string) *string {
+	return &str
+}
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		tweak  func(r *Record)
+		fields []string
+	}{
+		{ // valid
+			tweak: func(r *Record) {},
+		},
+		{ // minimal
+			tweak: func(r *Record) {
+				*r = Record{Version: "v1.0.0", ClusterID: "cluster"}
+			},
+		},
+		{ // empty
+			tweak: func(r *Record) {
+				*r = Record{}
+			},
+			fields: []string{"version", "clusterID"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.Timestamp = "yesterday"
+			},
+			fields: []string{"timestamp"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.Nodes[1].ID = ""
+			},
+			fields: []string{"nodes[1].id"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.Nodes[1].ID = r.Nodes[0].ID
+			},
+			fields: []string{"nodes[1].id"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.Nodes[0].Capacity = append(r.Nodes[0].Capacity, Resource{})
+			},
+			fields: []string{"nodes[0].capacity[1].resource", "nodes[0].capacity[1].value"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.Nodes[0].Allocatable = append(r.Nodes[0].Allocatable, Resource{Resource: "memory"})
+			},
+			fields: []string{"nodes[0].allocatable[1].value"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.Nodes[0].Conditions[1] = NodeCondition{Type: "Ready"}
+			},
+			fields: []string{"nodes[0].conditions[1].type", "nodes[0].conditions[1].status"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.Nodes[0].Taints = append(r.Nodes[0].Taints, TaintSummary{Effect: "NoSchedule", Count: -1}, TaintSummary{})
+			},
+			fields: []string{"nodes[0].taints[1].effect", "nodes[0].taints[1].count", "nodes[0].taints[2].effect"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.Nodes[0].ContainerRuntimeSemver.Minor = -1
+				r.Nodes[0].KubeletSemver.Prerelease = strPtr("")
+			},
+			fields: []string{"nodes[0].containerRuntimeSemver.minor", "nodes[0].kubeletSemver.prerelease"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.Nodes[0].Roles = []string{"master", "", "master"}
+			},
+			fields: []string{"nodes[0].roles[1]", "nodes[0].roles[2]"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.NodeGroups[0].Count = 0
+				r.NodeGroups[0].Capacity = append(r.NodeGroups[0].Capacity, Resource{Resource: "memory"})
+			},
+			fields: []string{"nodeGroups[0].count", "nodeGroups[0].capacity[1].value"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.Topology.ZoneCount = -1
+				r.Topology.Roles = append(r.Topology.Roles, RoleCount{Role: "master", Count: -1})
+			},
+			fields: []string{"topology.zoneCount", "topology.roles[1].role", "topology.roles[1].count"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.Storage.DefaultProvisioner = strPtr("")
+				r.Storage.PersistentVolumes.ReclaimPolicies = append(r.Storage.PersistentVolumes.ReclaimPolicies, ValueCount{Value: "Delete"})
+				r.Storage.PersistentVolumeClaims.Count = -1
+			},
+			fields: []string{"storage.defaultProvisioner", "storage.persistentVolumes.reclaimPolicies[1].value", "storage.persistentVolumeClaims.count"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.WorkloadFeatures.StatefulSets = int64Ptr(-1)
+				r.WorkloadFeatures.Jobs = int64Ptr(-2)
+			},
+			fields: []string{"workloadFeatures.statefulSets", "workloadFeatures.jobs"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.PodSecurity.Privileged = -1
+				r.PodSecurity.HostPID = 11
+			},
+			fields: []string{"podSecurity.hostPID", "podSecurity.privileged"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.Networking.IPFamily = strPtr("IPv5")
+				r.Networking.CNIPlugins = []string{"calico", "", "calico"}
+			},
+			fields: []string{"networking.ipFamily", "networking.cniPlugins[1]", "networking.cniPlugins[2]"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.Images.UntaggedCount = -1
+				r.Images.DigestFraction = 1.5
+			},
+			fields: []string{"images.untaggedCount", "images.digestFraction"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.Distribution.Name = ""
+				r.Distribution.Confidence = "certain"
+				r.Distribution.Signals = []string{"version", "version"}
+			},
+			fields: []string{"distribution.name", "distribution.confidence", "distribution.signals[1]"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.Summary.PodsCapacity = -1
+				r.Summary.Architectures[1] = ValueCount{Value: "amd64", Count: -1}
+			},
+			fields: []string{"summary.podsCapacity", "summary.architectures[1].value", "summary.architectures[1].count"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.Namespaces.Histograms[0].Kind = ""
+				r.Namespaces.Histograms[1].Kind = "pods"
+			},
+			fields: []string{"namespaces.histograms[0].kind"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.Namespaces.Histograms[1].Kind = "pods"
+			},
+			fields: []string{"namespaces.histograms[1].kind"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.Lifetimes[1].Kind = ""
+			},
+			fields: []string{"lifetimes[1].kind"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.Lifetimes[1].Kind = "pods"
+			},
+			fields: []string{"lifetimes[1].kind"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.Lifetimes[0].Live.Percentiles[0].Percentile = 0
+				r.Lifetimes[0].Deleted.Percentiles = []Percentile{{Percentile: 101}}
+			},
+			fields: []string{"lifetimes[0].live.percentiles[0].percentile", "lifetimes[0].deleted.percentiles[0].percentile"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.APIGroups[1].Name = ""
+			},
+			fields: []string{"apiGroups[1].name"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.APIGroups[1].Versions = []string{"v1", "", "v1"}
+			},
+			fields: []string{"apiGroups[1].versions[1]", "apiGroups[1].versions[2]"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.APIGroups[1].PreferredVersion = strPtr("v2")
+			},
+			fields: []string{"apiGroups[1].preferredVersion"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.Ecosystem.CatalogVersion = ""
+				r.Ecosystem.UnknownAPIGroups = -1
+			},
+			fields: []string{"ecosystem.catalogVersion", "ecosystem.unknownAPIGroups"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.Ecosystem.Projects = []string{"istio", "", "istio"}
+			},
+			fields: []string{"ecosystem.projects[1]", "ecosystem.projects[2]"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.Extensions[0].Name = ""
+			},
+			fields: []string{"extensions[0].name"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.Extensions[0].Name = "Example.com/hello"
+			},
+			fields: []string{"extensions[0].name"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.Extensions[1].Name = "example.com/"
+			},
+			fields: []string{"extensions[1].name"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.Extensions[1].Name = "/foo"
+			},
+			fields: []string{"extensions[1].name"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.Extensions[1].Name = "example.com/foo/bar"
+			},
+			fields: []string{"extensions[1].name"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.Extensions[1].Type = "list"
+				r.Extensions[2].IntValue = nil
+				r.Extensions[3].BoolValue = boolPtr(false)
+			},
+			fields: []string{"extensions[1].type", "extensions[2].intValue", "extensions[3].boolValue"},
+		},
+		{
+			tweak: func(r *Record) {
+				r.ExtensionDiagnostics[0].Source = ""
+				r.ExtensionDiagnostics[0].Count = -1
+				r.ExtensionDiagnostics[1].Errors = []string{""}
+			},
+			fields: []string{"extensionDiagnostics[0].source", "extensionDiagnostics[0].count", "extensionDiagnostics[1].errors[0]"},
+		},
+	}
+
+	for i, tc := range testCases {
+		rec := validRecord()
+		tc.tweak(&rec)
+		errs := rec.Validate()
+		if len(errs) != len(tc.fields) {
+			t.Errorf("[%d] expected %d errors, got %d: %v", i, len(tc.fields), len(errs), errs)
+			continue
+		}
+		for j := range errs {
+			if errs[j].Field != tc.fields[j] {
+				t.Errorf("[%d] expected error[%d] for field %q, got %q", i, j, tc.fields[j], errs[j].Field)
+			}
+		}
+	}
+}
+
+func TestValidateExtensionName(t *testing.T) {
+	testCases := []struct {
+		name   string
+		errstr string
+	}{
+		{name: "foo"},
+		{name: "example.com/foo"},
+		{name: "a.b-c.example/Foo_Bar"},
+		{name: "", errstr: "required"},
+		{name: "/foo", errstr: "prefix"},
+		{name: "-example.com/foo", errstr: "prefix"},
+		{name: "example..com/foo", errstr: "prefix"},
+		{name: strings.Repeat("a", 254) + "/foo", errstr: "prefix"},
+		{name: "example.com/", errstr: "name"},
+		{name: "a/b/c", errstr: "separated"},
+	}
+
+	for i, tc := range testCases {
+		msgs := ValidateExtensionName(tc.name)
+		if tc.errstr == "" {
+			if len(msgs) != 0 {
+				t.Errorf("[%d] unexpected errors for %q: %v", i, tc.name, msgs)
+			}
+			continue
+		}
+		if len(msgs) != 1 || !strings.Contains(msgs[0], tc.errstr) {
+			t.Errorf("[%d] expected one error containing %q for %q, got %v", i, tc.errstr, tc.name, msgs)
+		}
+	}
+}
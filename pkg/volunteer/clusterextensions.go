@@ -0,0 +1,151 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+)
+
+type extensionObjectLister interface {
+	// ListExtensionConfigMaps returns the ConfigMaps, in all namespaces,
+	// that match a label selector.
+	ListExtensionConfigMaps(selector string) ([]extensionConfigMap, error)
+	// SystemNamespaceAnnotations returns the annotations of the kube-system
+	// namespace.
+	SystemNamespaceAnnotations() (map[string]string, error)
+}
+
+// extensionConfigMap is the subset of a ConfigMap that extensions are read
+// from.
+type extensionConfigMap struct {
+	// name is the namespace and name of the ConfigMap, e.g.
+	// "kube-system/cluster-info".
+	name string
+	data map[string]string
+}
+
+// systemNamespaceSource is the source of the diagnostic of the extensions
+// read from annotations.
+const systemNamespaceSource = "namespace kube-system"
+
+// jsonExtensionsSuffix marks the keys of ConfigMap data that hold a JSON
+// document of extensions, rather than a single string extension.
+const jsonExtensionsSuffix = ".json"
+
+// clusterExtensionsLister is an implementation of the extensionsLister
+// interface that reads extensions from objects in the cluster, so that they
+// can be changed without redeploying the volunteer.  They are read again for
+// every report.
+type clusterExtensionsLister struct {
+	objects extensionObjectLister
+	// configMapSelector is a label selector for the ConfigMaps whose data
+	// are extensions, or empty to read no ConfigMaps.
+	configMapSelector string
+	// annotationPrefix is the prefix of the annotations of the kube-system
+	// namespace that are extensions, or empty to read no annotations.
+	annotationPrefix string
+}
+
+// ListExtensions returns a slice of report.Extensions containing the
+// custom extensions that the user may want to report.  Each ConfigMap is a
+// source of its own, in the order of their names, followed by the
+// annotations.  Failing to read the ConfigMaps or the annotations, e.g.
+// because RBAC denies it, is reported in the diagnostic of that source, so
+// that the other sources are still reported.
+func (c clusterExtensionsLister) ListExtensions() ([]extensionsSource, error) {
+	merger := newExtensionsMerger()
+
+	if c.configMapSelector != "" {
+		cms, err := c.objects.ListExtensionConfigMaps(c.configMapSelector)
+		if err != nil {
+			merger.add(report.ExtensionDiagnostic{
+				Source: "configmaps " + c.configMapSelector,
+				Errors: []string{fmt.Sprintf("failed to list ConfigMaps: %v", err)},
+			}, nil)
+		}
+		sort.Sort(extensionConfigMapsByName(cms))
+		for _, cm := range cms {
+			merger.add(extensionsFromConfigMap(cm))
+		}
+	}
+
+	if c.annotationPrefix != "" {
+		annotations, err := c.objects.SystemNamespaceAnnotations()
+		if err != nil {
+			merger.add(report.ExtensionDiagnostic{
+				Source: systemNamespaceSource,
+				Errors: []string{fmt.Sprintf("failed to get annotations: %v", err)},
+			}, nil)
+		} else {
+			merger.add(extensionsFromAnnotations(annotations, c.annotationPrefix))
+		}
+	}
+
+	return merger.sources, nil
+}
+
+// extensionsFromConfigMap converts the data of a ConfigMap to extensions.
+// Each key is a string extension, except for keys with a ".json" suffix,
+// which hold a JSON document in the same format as an extensions file.
+func extensionsFromConfigMap(cm extensionConfigMap) (report.ExtensionDiagnostic, []report.Extension) {
+	var all []report.Extension
+	var errs []string
+	for k, v := range cm.data {
+		if !strings.HasSuffix(k, jsonExtensionsSuffix) {
+			all = append(all, report.Extension{Name: k, Value: v, Type: report.ExtensionTypeString})
+			continue
+		}
+		es, keyErrs, err := parseExtensions([]byte(v))
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", k, err))
+			continue
+		}
+		for _, msg := range keyErrs {
+			errs = append(errs, fmt.Sprintf("%s: %s", k, msg))
+		}
+		all = append(all, es...)
+	}
+	extensions, diag := checkExtensions("configmap "+cm.name, all)
+	// We want to report the errors in a deterministic order.
+	sort.Strings(errs)
+	diag.Errors = append(errs, diag.Errors...)
+	return diag, extensions
+}
+
+// extensionsFromAnnotations converts the annotations that have a prefix to
+// string extensions, named by the whole annotation key.
+func extensionsFromAnnotations(annotations map[string]string, prefix string) (report.ExtensionDiagnostic, []report.Extension) {
+	var all []report.Extension
+	for k, v := range annotations {
+		if strings.HasPrefix(k, prefix) {
+			all = append(all, report.Extension{Name: k, Value: v, Type: report.ExtensionTypeString})
+		}
+	}
+	extensions, diag := checkExtensions(systemNamespaceSource, all)
+	return diag, extensions
+}
+
+// extensionConfigMapsByName sorts ConfigMaps by name.
+type extensionConfigMapsByName []extensionConfigMap
+
+func (s extensionConfigMapsByName) Len() int           { return len(s) }
+func (s extensionConfigMapsByName) Less(i, j int) bool { return s[i].name < s[j].name }
+func (s extensionConfigMapsByName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
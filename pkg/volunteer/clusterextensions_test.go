@@ -0,0 +1,160 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+// Fake out "list extension objects" calls.
+type fakeExtensionObjectLister struct {
+	configMaps       []extensionConfigMap
+	annotations      map[string]string
+	returnError      error
+	selectorRequests []string
+}
+
+var _ extensionObjectLister = &fakeExtensionObjectLister{}
+
+func (fake *fakeExtensionObjectLister) ListExtensionConfigMaps(selector string) ([]extensionConfigMap, error) {
+	fake.selectorRequests = append(fake.selectorRequests, selector)
+	return fake.configMaps, fake.returnError
+}
+
+func (fake *fakeExtensionObjectLister) SystemNamespaceAnnotations() (map[string]string, error) {
+	return fake.annotations, fake.returnError
+}
+
+func TestClusterExtensionsLister(t *testing.T) {
+	objects := &fakeExtensionObjectLister{
+		configMaps: []extensionConfigMap{
+			{name: "team-b/spartakus", data: map[string]string{
+				"tier": "gold",
+				"env":  "dev",
+			}},
+			{name: "team-a/spartakus", data: map[string]string{
+				"env":         "prod",
+				"facts.json":  `{"example.com/replicas": 3, "example.com/zones": ["a", "b"]}`,
+				"broken.json": `{`,
+			}},
+		},
+		annotations: map[string]string{
+			"example.com/owner":    "platform",
+			"example.com/Bad_Key/": "x",
+			"other.com/ignored":    "y",
+		},
+	}
+	lister := clusterExtensionsLister{
+		objects:           objects,
+		configMapSelector: "spartakus.k8s.io/extensions=true",
+		annotationPrefix:  "example.com/",
+	}
+
+	sources, err := lister.ListExtensions()
+	extensions, diagnostics := flattenExtensions(sources)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	if !reflect.DeepEqual(objects.selectorRequests, []string{"spartakus.k8s.io/extensions=true"}) {
+		t.Errorf("expected the selector to be passed, got %v", objects.selectorRequests)
+	}
+	expect := []report.Extension{
+		{Name: "env", Value: "prod", Type: "string"},
+		{Name: "example.com/replicas", Value: "3", Type: "int", IntValue: int64Ptr(3)},
+		{Name: "tier", Value: "gold", Type: "string"},
+		{Name: "example.com/owner", Value: "platform", Type: "string"},
+	}
+	if !reflect.DeepEqual(extensions, expect) {
+		t.Errorf("did not get expected result:\n%s", pretty.Compare(extensions, expect))
+	}
+	if len(diagnostics) != 3 {
+		t.Fatalf("expected 3 diagnostics, got %v", diagnostics)
+	}
+	if d := diagnostics[0]; d.Source != "configmap team-a/spartakus" || d.Count != 2 || len(d.Errors) != 2 ||
+		!strings.HasPrefix(d.Errors[0], "broken.json: failed to parse") ||
+		!strings.HasPrefix(d.Errors[1], `facts.json: "example.com/zones": unsupported value`) {
+		t.Errorf("unexpected diagnostic for team-a: %v", d)
+	}
+	expectDiags := []report.ExtensionDiagnostic{
+		{Source: "configmap team-b/spartakus", Count: 1, Errors: []string{`"env": duplicate of a key in configmap team-a/spartakus`}},
+		{Source: "namespace kube-system", Count: 1, Errors: []string{`"example.com/Bad_Key/": must consist of an optional DNS sub-domain prefix and a name, separated by a '/'`}},
+	}
+	if !reflect.DeepEqual(diagnostics[1:], expectDiags) {
+		t.Errorf("did not get expected result:\n%s", pretty.Compare(diagnostics[1:], expectDiags))
+	}
+}
+
+func TestClusterExtensionsListerSources(t *testing.T) {
+	testCases := []struct {
+		lister  clusterExtensionsLister
+		sources []string
+		errs    int // number of errors in the diagnostics
+	}{
+		{
+			lister:  clusterExtensionsLister{},
+			sources: nil,
+		},
+		{
+			lister:  clusterExtensionsLister{configMapSelector: "a=b"},
+			sources: []string{"configmap kube-system/info"},
+		},
+		{
+			lister:  clusterExtensionsLister{annotationPrefix: "example.com/"},
+			sources: []string{"namespace kube-system"},
+		},
+		{ // Failures are reported in the diagnostics, not as errors.
+			lister: clusterExtensionsLister{
+				objects:           &fakeExtensionObjectLister{returnError: fmt.Errorf("forbidden")},
+				configMapSelector: "a=b",
+				annotationPrefix:  "example.com/",
+			},
+			sources: []string{"configmaps a=b", "namespace kube-system"},
+			errs:    2,
+		},
+	}
+
+	for i, tc := range testCases {
+		if tc.lister.objects == nil {
+			tc.lister.objects = &fakeExtensionObjectLister{
+				configMaps: []extensionConfigMap{{name: "kube-system/info", data: map[string]string{"env": "prod"}}},
+			}
+		}
+		listed, err := tc.lister.ListExtensions()
+		_, diagnostics := flattenExtensions(listed)
+		if err != nil {
+			t.Errorf("[%d] unexpected error %q", i, err)
+		}
+		var sources []string
+		errs := 0
+		for _, d := range diagnostics {
+			sources = append(sources, d.Source)
+			errs += len(d.Errors)
+		}
+		if !reflect.DeepEqual(sources, tc.sources) {
+			t.Errorf("[%d] expected sources %v, got %v", i, tc.sources, sources)
+		}
+		if errs != tc.errs {
+			t.Errorf("[%d] expected %d errors, got %v", i, tc.errs, diagnostics)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+)
+
+type distributionSignalLister interface {
+	// ListDistributionSignals returns what a distribution can be recognized
+	// by.  The signals are only matched against the rules, never reported.
+	ListDistributionSignals() (distributionSignals, error)
+}
+
+// distributionSignals is the subset of the cluster's objects that
+// distributions are detected from.  The server version is not included,
+// since the serverVersioner already knows it.
+type distributionSignals struct {
+	// nodeLabels are the labels of every node.
+	nodeLabels []map[string]string
+	// providerIDs are the provider IDs of the nodes that have one.
+	providerIDs []string
+	// namespaces are the names of the namespaces.
+	namespaces []string
+}
+
+// distributionRules is a versioned table of rules for detecting
+// distributions.  It can be loaded from a JSON file, so that the rules can be
+// updated without changing the volunteer.
+type distributionRules struct {
+	// Version must be changed whenever the rules are, so that reports from
+	// different rules can be told apart.
+	Version string `json:"version"`
+	// Rules are tried in order.  The rule with the most kinds of matching
+	// signals wins, and the first one wins a tie.
+	Rules []distributionRule `json:"rules"`
+}
+
+type distributionRule struct {
+	// Name is the identifier reported for the distribution.
+	Name string `json:"name"`
+	// Versions are substrings of the server version, e.g. "+k3s".
+	Versions []string `json:"versions,omitempty"`
+	// NodeLabels are labels that any node has, either as "key", which
+	// matches any value, or as "key=value".
+	NodeLabels []string `json:"nodeLabels,omitempty"`
+	// ProviderIDs are prefixes of the provider ID of any node, e.g.
+	// "kind://".
+	ProviderIDs []string `json:"providerIDs,omitempty"`
+	// Namespaces are the names of namespaces that exist.  They are a weak
+	// signal, since anyone can create them.
+	Namespaces []string `json:"namespaces,omitempty"`
+}
+
+// The kinds of signals, as reported in KubernetesDistribution.Signals.
+const (
+	versionSignal    = "version"
+	nodeLabelSignal  = "nodeLabel"
+	providerIDSignal = "providerID"
+	namespaceSignal  = "namespace"
+)
+
+// unknownDistribution is reported when no rule matches.
+const unknownDistribution = "unknown"
+
+// defaultDistributionRules are used unless rules are loaded from a file.
+var defaultDistributionRules = distributionRules{
+	Version: "1",
+	Rules: []distributionRule{
+		{Name: "gke", Versions: []string{"-gke."}, NodeLabels: []string{"cloud.google.com/gke-nodepool"}},
+		{Name: "eks", Versions: []string{"-eks-"}, NodeLabels: []string{"eks.amazonaws.com/nodegroup", "eks.amazonaws.com/compute-type"}},
+		{Name: "aks", NodeLabels: []string{"kubernetes.azure.com/cluster", "kubernetes.azure.com/role"}},
+		{Name: "doks", NodeLabels: []string{"doks.digitalocean.com/node-id"}},
+		{Name: "openshift", NodeLabels: []string{"node.openshift.io/os_id"}, Namespaces: []string{"openshift-apiserver", "openshift-config"}},
+		{Name: "rke2", Versions: []string{"+rke2"}},
+		{Name: "k3s", Versions: []string{"+k3s"}, NodeLabels: []string{"node.kubernetes.io/instance-type=k3s"}},
+		{Name: "kind", ProviderIDs: []string{"kind://"}, Namespaces: []string{"local-path-storage"}},
+		{Name: "minikube", NodeLabels: []string{"minikube.k8s.io/name"}},
+		{Name: "microk8s", NodeLabels: []string{"microk8s.io/cluster"}},
+	},
+}
+
+// validate checks that every rule has a name and at least one signal.
+func (rules *distributionRules) validate() error {
+	if rules.Version == "" {
+		return fmt.Errorf("version is required")
+	}
+	for i, r := range rules.Rules {
+		if r.Name == "" {
+			return fmt.Errorf("rules[%d].name is required", i)
+		}
+		if len(r.Versions)+len(r.NodeLabels)+len(r.ProviderIDs)+len(r.Namespaces) == 0 {
+			return fmt.Errorf("rules[%d] has no signals", i)
+		}
+	}
+	return nil
+}
+
+// detectDistribution matches the server version and the signals against the
+// rules.
+func detectDistribution(rules distributionRules, serverVersion string, signals distributionSignals) *report.KubernetesDistribution {
+	dist := &report.KubernetesDistribution{
+		RulesVersion: rules.Version,
+		Name:         unknownDistribution,
+		Confidence:   "none",
+	}
+	for _, r := range rules.Rules {
+		matched := matchDistributionRule(r, serverVersion, signals)
+		if len(matched) > len(dist.Signals) {
+			dist.Name = r.Name
+			dist.Signals = matched
+		}
+	}
+	dist.Confidence = confidenceOf(dist.Signals)
+	return dist
+}
+
+// matchDistributionRule returns the kinds of signals that match a rule.
+func matchDistributionRule(r distributionRule, serverVersion string, signals distributionSignals) []string {
+	var matched []string
+	if containsAny(serverVersion, r.Versions) {
+		matched = append(matched, versionSignal)
+	}
+	for _, labels := range signals.nodeLabels {
+		if hasAnyLabel(labels, r.NodeLabels) {
+			matched = append(matched, nodeLabelSignal)
+			break
+		}
+	}
+	for _, id := range signals.providerIDs {
+		if hasAnyPrefix(id, r.ProviderIDs) {
+			matched = append(matched, providerIDSignal)
+			break
+		}
+	}
+	for _, ns := range signals.namespaces {
+		if isOneOf(ns, r.Namespaces) {
+			matched = append(matched, namespaceSignal)
+			break
+		}
+	}
+	return matched
+}
+
+// confidenceOf rates a detection by the kinds of signals that matched: two
+// or more are "high", one is "medium", and namespaces alone are "low".
+func confidenceOf(signals []string) string {
+	switch {
+	case len(signals) >= 2:
+		return "high"
+	case len(signals) == 1 && signals[0] == namespaceSignal:
+		return "low"
+	case len(signals) == 1:
+		return "medium"
+	}
+	return "none"
+}
+
+func containsAny(s string, substrings []string) bool {
+	for _, sub := range substrings {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func isOneOf(s string, values []string) bool {
+	for _, v := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAnyLabel tells whether labels match any selector, either "key" or
+// "key=value".
+func hasAnyLabel(labels map[string]string, selectors []string) bool {
+	for _, sel := range selectors {
+		parts := strings.SplitN(sel, "=", 2)
+		value, found := labels[parts[0]]
+		if found && (len(parts) == 1 || value == parts[1]) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestDetectDistribution(t *testing.T) {
+	testCases := []struct {
+		version string
+		signals distributionSignals
+		expect  *report.KubernetesDistribution
+	}{
+		{
+			version: "v1.4.6",
+			expect:  &report.KubernetesDistribution{RulesVersion: "1", Name: "unknown", Confidence: "none"},
+		},
+		{
+			version: "v1.27.3-gke.100",
+			signals: distributionSignals{
+				nodeLabels:  []map[string]string{{"kubernetes.io/os": "linux"}, {"cloud.google.com/gke-nodepool": "default-pool"}},
+				providerIDs: []string{"gce://project/us-central1-a/node-1"},
+			},
+			expect: &report.KubernetesDistribution{RulesVersion: "1", Name: "gke", Confidence: "high", Signals: []string{"version", "nodeLabel"}},
+		},
+		{
+			version: "v1.27.4-eks-2d98532",
+			expect:  &report.KubernetesDistribution{RulesVersion: "1", Name: "eks", Confidence: "medium", Signals: []string{"version"}},
+		},
+		{
+			version: "v1.27.4+k3s1",
+			signals: distributionSignals{
+				nodeLabels: []map[string]string{{"node.kubernetes.io/instance-type": "k3s"}},
+			},
+			expect: &report.KubernetesDistribution{RulesVersion: "1", Name: "k3s", Confidence: "high", Signals: []string{"version", "nodeLabel"}},
+		},
+		{ // the label value must match too
+			version: "v1.27.4",
+			signals: distributionSignals{
+				nodeLabels: []map[string]string{{"node.kubernetes.io/instance-type": "m5.large"}},
+			},
+			expect: &report.KubernetesDistribution{RulesVersion: "1", Name: "unknown", Confidence: "none"},
+		},
+		{ // the rule with more kinds of signals wins
+			version: "v1.27.4+rke2r1",
+			signals: distributionSignals{
+				providerIDs: []string{"kind://docker/kind/kind-control-plane"},
+				namespaces:  []string{"default", "local-path-storage"},
+			},
+			expect: &report.KubernetesDistribution{RulesVersion: "1", Name: "kind", Confidence: "high", Signals: []string{"providerID", "namespace"}},
+		},
+		{
+			version: "v1.26.0",
+			signals: distributionSignals{
+				namespaces: []string{"default", "openshift-config"},
+			},
+			expect: &report.KubernetesDistribution{RulesVersion: "1", Name: "openshift", Confidence: "low", Signals: []string{"namespace"}},
+		},
+	}
+
+	for i, tc := range testCases {
+		dist := detectDistribution(defaultDistributionRules, tc.version, tc.signals)
+		if !reflect.DeepEqual(dist, tc.expect) {
+			t.Errorf("[%d] did not get expected result:\n%s", i, pretty.Compare(dist, tc.expect))
+		}
+	}
+}
+
+func TestParseDistributionRules(t *testing.T) {
+	testCases := []struct {
+		input  string
+		errstr string
+		expect distributionRules
+	}{
+		{
+			input: `{"version": "site-2", "rules": [{"name": "acme", "versions": ["-acme."], "nodeLabels": ["acme.example.com/pool"]}]}`,
+			expect: distributionRules{
+				Version: "site-2",
+				Rules: []distributionRule{
+					{Name: "acme", Versions: []string{"-acme."}, NodeLabels: []string{"acme.example.com/pool"}},
+				},
+			},
+		},
+		{
+			input:  `{"rules": []}`,
+			errstr: "version is required",
+		},
+		{
+			input:  `{"version": "1", "rules": [{"versions": ["-acme."]}]}`,
+			errstr: "rules[0].name is required",
+		},
+		{
+			input:  `{"version": "1", "rules": [{"name": "acme"}]}`,
+			errstr: "rules[0] has no signals",
+		},
+		{
+			input:  `[]`,
+			errstr: "failed to parse",
+		},
+	}
+
+	for i, tc := range testCases {
+		var rules distributionRules
+		err := parseRules([]byte(tc.input), "distribution", &rules)
+		if err != nil && tc.errstr == "" {
+			t.Errorf("[%d] unexpected error %q", i, err)
+		} else if err != nil {
+			if !strings.Contains(err.Error(), tc.errstr) {
+				t.Errorf("[%d] expected error containing %q, got %q", i, tc.errstr, err)
+			}
+		} else if err == nil && tc.errstr != "" {
+			t.Errorf("[%d] expected error containing %q: no error", i, tc.errstr)
+		} else if !reflect.DeepEqual(rules, tc.expect) {
+			t.Errorf("[%d] did not get expected result:\n%s", i, pretty.Compare(rules, tc.expect))
+		}
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+)
+
+type systemWorkloadLister interface {
+	// ListSystemWorkloads returns the names of the Deployments and DaemonSets
+	// in the kube-system namespace.  They are only matched against the
+	// catalog, never reported.
+	ListSystemWorkloads() ([]string, error)
+}
+
+// ecosystemCatalogVersion must be changed whenever ecosystemCatalog is, so
+// that reports from different catalogs can be told apart.
+const ecosystemCatalogVersion = "1"
+
+// ecosystemCatalog is a list of well-known projects and the signals that they
+// are installed in a cluster.
+var ecosystemCatalog = []struct {
+	// id is the identifier reported for the project.
+	id string
+	// apiGroups are the API group domains that the project serves, e.g.
+	// "istio.io" matches "istio.io" and "networking.istio.io".
+	apiGroups []string
+	// workloads are the names of the project's Deployments and DaemonSets in
+	// kube-system.  A name also matches with a suffix, e.g. "kube-flannel"
+	// matches "kube-flannel-ds".
+	workloads []string
+}{
+	{id: "calico", apiGroups: []string{"projectcalico.org"}, workloads: []string{"calico-node", "calico-kube-controllers", "calico-policy-controller"}},
+	{id: "cert-manager", apiGroups: []string{"cert-manager.io", "certmanager.k8s.io"}},
+	{id: "cilium", apiGroups: []string{"cilium.io"}, workloads: []string{"cilium", "cilium-operator"}},
+	{id: "coredns", workloads: []string{"coredns"}},
+	{id: "flannel", workloads: []string{"kube-flannel"}},
+	{id: "heapster", workloads: []string{"heapster"}},
+	{id: "helm", workloads: []string{"tiller-deploy"}},
+	{id: "istio", apiGroups: []string{"istio.io"}},
+	{id: "kube-dns", workloads: []string{"kube-dns"}},
+	{id: "kubernetes-dashboard", workloads: []string{"kubernetes-dashboard"}},
+	{id: "linkerd", apiGroups: []string{"linkerd.io"}},
+	{id: "metrics-server", apiGroups: []string{"metrics.k8s.io"}, workloads: []string{"metrics-server"}},
+	{id: "prometheus-operator", apiGroups: []string{"monitoring.coreos.com"}},
+	{id: "weave-net", workloads: []string{"weave-net"}},
+}
+
+// detectEcosystem matches the served API groups and the kube-system workloads
+// against the catalog.
+func detectEcosystem(groups []report.APIGroup, workloads []string) *report.Ecosystem {
+	detected := map[string]bool{}
+	var unknown int64
+	for _, g := range groups {
+		found := false
+		for _, p := range ecosystemCatalog {
+			if matchesAPIGroup(g.Name, p.apiGroups) {
+				detected[p.id] = true
+				found = true
+			}
+		}
+		if !found && !isBuiltinAPIGroup(g.Name) {
+			unknown++
+		}
+	}
+	for _, w := range workloads {
+		for _, p := range ecosystemCatalog {
+			if matchesWorkload(w, p.workloads) {
+				detected[p.id] = true
+			}
+		}
+	}
+
+	eco := &report.Ecosystem{
+		CatalogVersion:   ecosystemCatalogVersion,
+		UnknownAPIGroups: unknown,
+	}
+	for id := range detected {
+		eco.Projects = append(eco.Projects, id)
+	}
+	// We want to report the projects in a deterministic order.
+	sort.Strings(eco.Projects)
+	return eco
+}
+
+func matchesAPIGroup(group string, domains []string) bool {
+	for _, d := range domains {
+		if group == d || strings.HasSuffix(group, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesWorkload(name string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if name == p || strings.HasPrefix(name, p+"-") {
+			return true
+		}
+	}
+	return false
+}
+
+// isBuiltinAPIGroup tells whether a group is served by kubernetes itself:
+// the core group, the original groups with no domain such as "batch", and the
+// groups under k8s.io.
+func isBuiltinAPIGroup(group string) bool {
+	return !strings.Contains(group, ".") || strings.HasSuffix(group, ".k8s.io")
+}
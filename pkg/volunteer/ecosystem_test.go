@@ -0,0 +1,83 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestDetectEcosystem(t *testing.T) {
+	testCases := []struct {
+		groups    []string
+		workloads []string
+		expect    *report.Ecosystem
+	}{
+		{
+			expect: &report.Ecosystem{CatalogVersion: ecosystemCatalogVersion},
+		},
+		{
+			groups: []string{"", "apps", "batch", "rbac.authorization.k8s.io", "storage.k8s.io"},
+			expect: &report.Ecosystem{CatalogVersion: ecosystemCatalogVersion},
+		},
+		{
+			groups: []string{
+				"config.istio.io",
+				"networking.istio.io",
+				"monitoring.coreos.com",
+				"metrics.k8s.io",
+				"example.com",
+				"stable.example.com",
+				"notistio.io",
+			},
+			expect: &report.Ecosystem{
+				CatalogVersion:   ecosystemCatalogVersion,
+				Projects:         []string{"istio", "metrics-server", "prometheus-operator"},
+				UnknownAPIGroups: 3,
+			},
+		},
+		{
+			workloads: []string{"kube-dns", "kube-flannel-ds-amd64", "calico-node", "kube-dnsmasq", "my-app"},
+			expect: &report.Ecosystem{
+				CatalogVersion: ecosystemCatalogVersion,
+				Projects:       []string{"calico", "flannel", "kube-dns"},
+			},
+		},
+		{
+			groups:    []string{"crd.projectcalico.org"},
+			workloads: []string{"calico-node"},
+			expect: &report.Ecosystem{
+				CatalogVersion: ecosystemCatalogVersion,
+				Projects:       []string{"calico"},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		groups := []report.APIGroup{}
+		for _, g := range tc.groups {
+			groups = append(groups, report.APIGroup{Name: g})
+		}
+		eco := detectEcosystem(groups, tc.workloads)
+		if !reflect.DeepEqual(eco, tc.expect) {
+			t.Errorf("[%d] did not get expected result:\n%s", i, pretty.Compare(eco, tc.expect))
+		}
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+	"github.com/thockin/logr"
+)
+
+// hookExtensionsLister is an implementation of the extensionsLister
+// interface that runs the executables in a directory, and reads extensions
+// from what they print, so that sites can report facts that only a script
+// can compute.  The hooks are run again for every report.
+type hookExtensionsLister struct {
+	log logr.Logger
+	// dir is the directory of the hooks, or empty to run no hooks.
+	dir string
+	// timeout is how long each hook may run before it is killed.
+	timeout time.Duration
+}
+
+// ListExtensions returns a slice of report.Extensions containing the
+// custom extensions that the hooks print.  Each hook is a source of its own,
+// in the order of their names.  Files that are not executable, or that have
+// a leading `.`, are not run.
+func (h hookExtensionsLister) ListExtensions() ([]extensionsSource, error) {
+	merger := newExtensionsMerger()
+
+	if h.dir == "" {
+		return merger.sources, nil
+	}
+
+	fis, err := ioutil.ReadDir(h.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open extensions hooks directory: %v", err)
+	}
+	for _, fi := range fis {
+		if fi.IsDir() || strings.HasPrefix(fi.Name(), ".") || fi.Mode().Perm()&0111 == 0 {
+			continue
+		}
+		merger.add(h.runHook(fi.Name()))
+	}
+
+	return merger.sources, nil
+}
+
+// runHook runs a hook and parses what it prints to stdout, in the same
+// format as an extensions file.  Its extensions are namespaced by its name,
+// as if they were nested in an object: a hook named "inventory.sh" that
+// prints {"racks": 4} reports "inventory.racks".
+func (h hookExtensionsLister) runHook(name string) (report.ExtensionDiagnostic, []report.Extension) {
+	diag := report.ExtensionDiagnostic{Source: "hook " + name}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(filepath.Join(h.dir, name))
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Run the hook in a process group of its own, so that a timeout kills
+	// any processes it started as well.  Otherwise a child that inherited
+	// stdout would keep us waiting until it exits.
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := cmd.Start(); err != nil {
+		diag.Errors = append(diag.Errors, fmt.Sprintf("failed to run: %v", err))
+		return diag, nil
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+	var err error
+	timedOut := false
+	select {
+	case err = <-done:
+	case <-time.After(h.timeout):
+		timedOut = true
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+		err = <-done
+	}
+	if stderr.Len() > 0 {
+		h.log.Infof("extensions hook %s wrote to stderr: %s", name, strings.TrimSpace(stderr.String()))
+	}
+	if timedOut {
+		diag.Errors = append(diag.Errors, fmt.Sprintf("timed out after %v", h.timeout))
+		return diag, nil
+	}
+	if err != nil {
+		diag.Errors = append(diag.Errors, fmt.Sprintf("failed to run: %v", err))
+		return diag, nil
+	}
+	h.log.V(1).Infof("extensions hook %s exited with status 0", name)
+
+	// A hook that prints nothing has no extensions.
+	if len(bytes.TrimSpace(stdout.Bytes())) == 0 {
+		return diag, nil
+	}
+	all, errs, err := parseExtensions(stdout.Bytes())
+	if err != nil {
+		diag.Errors = append(diag.Errors, err.Error())
+		return diag, nil
+	}
+	namespace := hookNamespace(name)
+	for i := range all {
+		all[i].Name = namespace + "." + all[i].Name
+	}
+	extensions, diag := checkExtensions(diag.Source, all)
+	diag.Errors = append(errs, diag.Errors...)
+	return diag, extensions
+}
+
+// hookNamespace returns the name that a hook's extensions are namespaced
+// by: its file name without the extension, e.g. "inventory" for
+// "inventory.sh".
+func hookNamespace(name string) string {
+	if ns := strings.TrimSuffix(name, filepath.Ext(name)); ns != "" {
+		return ns
+	}
+	return name
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+	"github.com/kylelemons/godebug/pretty"
+	logrtest "github.com/thockin/logr/testing"
+)
+
+func TestHookExtensionsLister(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hooks")
+	if err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	hooks := []struct {
+		name string
+		mode os.FileMode
+		data string
+	}{
+		{"a-inventory.sh", 0755, "#!/bin/sh\necho 'checking' >&2\necho '{\"racks\": 4, \"example.com/site\": \"lab\", \"rooms\": null}'\n"},
+		{"b-empty", 0755, "#!/bin/sh\n"},
+		{"c-fails", 0755, "#!/bin/sh\necho '{\"x\": 1}'\nexit 3\n"},
+		{"d-garbage", 0755, "#!/bin/sh\necho 'not json'\n"},
+		{"e-slow", 0755, "#!/bin/sh\nexec sleep 5\n"},
+		{"f-slow-child", 0755, "#!/bin/sh\nsleep 5\necho '{}'\n"},
+		{"g-not-executable", 0644, "#!/bin/sh\necho '{\"x\": 1}'\n"},
+		{".h-hidden", 0755, "#!/bin/sh\necho '{\"x\": 1}'\n"},
+	}
+	for _, h := range hooks {
+		if err := ioutil.WriteFile(filepath.Join(dir, h.name), []byte(h.data), h.mode); err != nil {
+			t.Fatalf("failed to write %s: %v", h.name, err)
+		}
+	}
+
+	lister := hookExtensionsLister{
+		log:     &logrtest.TestLogger{T: t},
+		dir:     dir,
+		timeout: 500 * time.Millisecond,
+	}
+	start := time.Now()
+	sources, err := lister.ListExtensions()
+	extensions, diagnostics := flattenExtensions(sources)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	// Each slow hook, and any process it started, must be killed at its
+	// timeout.
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("expected slow hooks to be killed, but listing took %v", elapsed)
+	}
+	expect := []report.Extension{
+		{Name: "a-inventory.example.com/site", Value: "lab", Type: report.ExtensionTypeString},
+		{Name: "a-inventory.racks", Value: "4", Type: report.ExtensionTypeInt, IntValue: int64Ptr(4)},
+	}
+	if !reflect.DeepEqual(extensions, expect) {
+		t.Errorf("did not get expected extensions:\n%s", pretty.Compare(extensions, expect))
+	}
+
+	expectErrors := []struct {
+		source string
+		errstr string
+	}{
+		{"hook a-inventory.sh", `"rooms": unsupported value null`},
+		{"hook b-empty", ""},
+		{"hook c-fails", "exit status 3"},
+		{"hook d-garbage", "failed to parse"},
+		{"hook e-slow", "timed out"},
+		{"hook f-slow-child", "timed out"},
+	}
+	if len(diagnostics) != len(expectErrors) {
+		t.Fatalf("expected %d diagnostics, got %v", len(expectErrors), diagnostics)
+	}
+	for i, tc := range expectErrors {
+		d := diagnostics[i]
+		if d.Source != tc.source {
+			t.Errorf("[%d] expected source %q, got %q", i, tc.source, d.Source)
+		}
+		if tc.errstr == "" && len(d.Errors) != 0 {
+			t.Errorf("[%d] unexpected errors %v", i, d.Errors)
+		} else if tc.errstr != "" && (len(d.Errors) != 1 || !strings.Contains(d.Errors[0], tc.errstr)) {
+			t.Errorf("[%d] expected error %q, got %v", i, tc.errstr, d.Errors)
+		}
+	}
+	if diagnostics[0].Count != 2 {
+		t.Errorf("expected 2 extensions from the first hook, got %d", diagnostics[0].Count)
+	}
+}
+
+func TestHookExtensionsListerMissingDir(t *testing.T) {
+	lister := hookExtensionsLister{
+		log:     &logrtest.TestLogger{T: t},
+		dir:     "/does/not/exist",
+		timeout: time.Second,
+	}
+	if _, err := lister.ListExtensions(); err == nil {
+		t.Errorf("expected an error for a missing directory")
+	}
+}
+
+func TestHookNamespace(t *testing.T) {
+	testCases := []struct {
+		name   string
+		expect string
+	}{
+		{"inventory", "inventory"},
+		{"inventory.sh", "inventory"},
+		{"inventory.v2.py", "inventory.v2"},
+		{".sh", ".sh"},
+	}
+	for i, tc := range testCases {
+		if got := hookNamespace(tc.name); got != tc.expect {
+			t.Errorf("[%d] expected %q, got %q", i, tc.expect, got)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"strings"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+)
+
+const (
+	// dockerHubRegistry is the registry of images without a registry host.
+	dockerHubRegistry = "docker.io"
+	// privateRegistry is reported for registries that are not well-known.
+	privateRegistry = "private"
+)
+
+// knownRegistries maps the hosts of well-known public registries to the
+// name they are reported as.
+var knownRegistries = map[string]string{
+	"docker.io":            dockerHubRegistry,
+	"index.docker.io":      dockerHubRegistry,
+	"registry-1.docker.io": dockerHubRegistry,
+	"gcr.io":               "gcr.io",
+	"ghcr.io":              "ghcr.io",
+	"mcr.microsoft.com":    "mcr.microsoft.com",
+	"public.ecr.aws":       "public.ecr.aws",
+	"quay.io":              "quay.io",
+	"registry.k8s.io":      "registry.k8s.io",
+}
+
+// knownRegistrySuffixes maps the domains whose hosts all belong to a
+// well-known public registry, such as "k8s.gcr.io", to the name they are
+// reported as.
+var knownRegistrySuffixes = map[string]string{
+	".gcr.io": "gcr.io",
+}
+
+// parseImage splits an image reference into its registry host, or "" if it
+// has none, and tells whether it has a tag and a digest.
+func parseImage(image string) (host string, tagged, digested bool) {
+	if i := strings.Index(image, "@"); i >= 0 {
+		digested = true
+		image = image[:i]
+	}
+	// Like docker, the first component is a host only if it looks like one.
+	if i := strings.Index(image, "/"); i >= 0 {
+		first := image[:i]
+		if strings.ContainsAny(first, ".:") || first == "localhost" {
+			host = first
+			image = image[i+1:]
+		}
+	}
+	// Any remaining ":" separates the tag, since the port was in the host.
+	tagged = strings.Contains(image, ":")
+	return host, tagged, digested
+}
+
+// registryOf returns the name that the registry of an image is reported as.
+func registryOf(host string) string {
+	if host == "" {
+		return dockerHubRegistry
+	}
+	// Registry hosts are case-insensitive.
+	host = strings.ToLower(host)
+	if name, found := knownRegistries[host]; found {
+		return name
+	}
+	for suffix, name := range knownRegistrySuffixes {
+		if strings.HasSuffix(host, suffix) {
+			return name
+		}
+	}
+	return privateRegistry
+}
+
+// imagesFromList builds the report section for a list of images.
+func imagesFromList(images []string) *report.Images {
+	im := &report.Images{ContainerCount: int64(len(images))}
+	registries := map[string]int64{}
+	for _, image := range images {
+		host, tagged, digested := parseImage(image)
+		registries[registryOf(host)]++
+		switch {
+		case digested:
+			im.DigestCount++
+		case tagged:
+			im.TagCount++
+		default:
+			im.UntaggedCount++
+		}
+	}
+	im.Registries = valueCounts(registries)
+	if im.ContainerCount > 0 {
+		im.DigestFraction = float64(im.DigestCount) / float64(im.ContainerCount)
+	}
+	return im
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestParseImage(t *testing.T) {
+	testCases := []struct {
+		image    string
+		host     string
+		tagged   bool
+		digested bool
+	}{
+		{image: "nginx", host: ""},
+		{image: "nginx:1.11", host: "", tagged: true},
+		{image: "library/nginx:1.11", host: "", tagged: true},
+		{image: "gcr.io/google_containers/pause-amd64:3.0", host: "gcr.io", tagged: true},
+		{image: "localhost/app", host: "localhost"},
+		{image: "registry:5000/app", host: "registry:5000"},
+		{image: "registry.example.com:5000/team/app:v1", host: "registry.example.com:5000", tagged: true},
+		{image: "quay.io/coreos/etcd@sha256:0123", host: "quay.io", digested: true},
+		{image: "nginx:1.11@sha256:0123", host: "", tagged: true, digested: true},
+	}
+
+	for i, tc := range testCases {
+		host, tagged, digested := parseImage(tc.image)
+		if host != tc.host || tagged != tc.tagged || digested != tc.digested {
+			t.Errorf("[%d] expected (%q, %v, %v), got (%q, %v, %v)", i, tc.host, tc.tagged, tc.digested, host, tagged, digested)
+		}
+	}
+}
+
+func TestRegistryOf(t *testing.T) {
+	testCases := []struct {
+		host   string
+		expect string
+	}{
+		{host: "", expect: "docker.io"},
+		{host: "docker.io", expect: "docker.io"},
+		{host: "index.docker.io", expect: "docker.io"},
+		{host: "gcr.io", expect: "gcr.io"},
+		{host: "k8s.gcr.io", expect: "gcr.io"},
+		{host: "Quay.IO", expect: "quay.io"},
+		{host: "registry.k8s.io", expect: "registry.k8s.io"},
+		{host: "localhost", expect: "private"},
+		{host: "registry.example.com:5000", expect: "private"},
+		{host: "notgcr.io", expect: "private"},
+	}
+
+	for i, tc := range testCases {
+		if registry := registryOf(tc.host); registry != tc.expect {
+			t.Errorf("[%d] expected %q, got %q", i, tc.expect, registry)
+		}
+	}
+}
+
+func TestImagesFromList(t *testing.T) {
+	testCases := []struct {
+		input  []string
+		expect *report.Images
+	}{
+		{
+			input:  nil,
+			expect: &report.Images{},
+		},
+		{
+			input: []string{
+				"nginx",
+				"nginx:1.11",
+				"gcr.io/google_containers/pause-amd64:3.0",
+				"registry.example.com/team/app@sha256:0123",
+			},
+			expect: &report.Images{
+				ContainerCount: 4,
+				Registries: []report.ValueCount{
+					{Value: "docker.io", Count: 2},
+					{Value: "gcr.io", Count: 1},
+					{Value: "private", Count: 1},
+				},
+				DigestCount:    1,
+				TagCount:       2,
+				UntaggedCount:  1,
+				DigestFraction: 0.25,
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		images := imagesFromList(tc.input)
+		if !reflect.DeepEqual(images, tc.expect) {
+			t.Errorf("[%d] did not get expected result:\n%s", i, pretty.Compare(images, tc.expect))
+		}
+	}
+}
@@ -19,49 +19,123 @@ package volunteer
 import (
 	"crypto/md5"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"sort"
 	"strings"
 
 	"github.com/kubernetes-incubator/spartakus/pkg/report"
 	kclient "k8s.io/client-go/1.5/kubernetes"
 	kapi "k8s.io/client-go/1.5/pkg/api"
+	kerrors "k8s.io/client-go/1.5/pkg/api/errors"
+	kunversioned "k8s.io/client-go/1.5/pkg/api/unversioned"
 	kv1 "k8s.io/client-go/1.5/pkg/api/v1"
+	kstorage "k8s.io/client-go/1.5/pkg/apis/storage/v1beta1"
+	klabels "k8s.io/client-go/1.5/pkg/labels"
 	krest "k8s.io/client-go/1.5/rest"
 )
 
-// cloudProviders is a whitelist of the known Kubernetes cloud providers.
-var cloudProviders map[string]bool = map[string]bool{
-	// https://github.com/kubernetes/kubernetes/blob/master/pkg/cloudprovider/providers/aws/aws.go#L55
-	"aws": true,
-	// https://github.com/kubernetes/kubernetes/blob/master/pkg/cloudprovider/providers/azure/azure.go#L34
-	"azure": true,
-	// https://github.com/kubernetes/kubernetes/blob/master/pkg/cloudprovider/providers/cloudstack/cloudstack.go#L30
-	"cloudstack": true,
-	// https://github.com/kubernetes/kubernetes/blob/master/pkg/cloudprovider/providers/gce/gce.go#L55
-	"gce": true,
-	// https://github.com/kubernetes/kubernetes/blob/master/pkg/cloudprovider/providers/mesos/mesos.go#L37
-	"mesos": true,
-	// https://github.com/kubernetes/kubernetes/blob/master/pkg/cloudprovider/providers/openstack/openstack.go#L44
-	"openstack": true,
-	// https://github.com/kubernetes/kubernetes/blob/master/pkg/cloudprovider/providers/ovirt/ovirt.go#L39
-	"ovirt": true,
-	// https://github.com/kubernetes/kubernetes/blob/master/pkg/cloudprovider/providers/photon/photon.go#L43
-	"photon": true,
-	// https://github.com/kubernetes/kubernetes/blob/master/pkg/cloudprovider/providers/rackspace/rackspace.go#L48
-	"rackspace": true,
-	// https://github.com/kubernetes/kubernetes/blob/master/pkg/cloudprovider/providers/vsphere/vsphere.go#L52
-	"vsphere": true,
-}
-
 type nodeLister interface {
 	ListNodes() ([]report.Node, error)
 }
 
+// kindGroupVersions are the API group versions that serve each kind of
+// object that is listed, in order of preference.  Older versions are tried
+// when a newer one is not served, so that the kinds are found whichever
+// version of kubernetes serves them.
+var kindGroupVersions = map[string][]string{
+	"configmaps":               {"/api/v1"},
+	"cronjobs":                 {"/apis/batch/v1", "/apis/batch/v1beta1", "/apis/batch/v2alpha1"},
+	"daemonsets":               {"/apis/apps/v1", "/apis/extensions/v1beta1"},
+	"deployments":              {"/apis/apps/v1", "/apis/extensions/v1beta1"},
+	"horizontalpodautoscalers": {"/apis/autoscaling/v1"},
+	"ingresses":                {"/apis/networking.k8s.io/v1", "/apis/extensions/v1beta1"},
+	"jobs":                     {"/apis/batch/v1"},
+	"limitranges":              {"/api/v1"},
+	"namespaces":               {"/api/v1"},
+	"networkpolicies":          {"/apis/networking.k8s.io/v1", "/apis/extensions/v1beta1"},
+	"nodes":                    {"/api/v1"},
+	"persistentvolumeclaims":   {"/api/v1"},
+	"petsets":                  {"/apis/apps/v1alpha1"},
+	"poddisruptionbudgets":     {"/apis/policy/v1", "/apis/policy/v1beta1", "/apis/policy/v1alpha1"},
+	"pods":                     {"/api/v1"},
+	"replicasets":              {"/apis/apps/v1", "/apis/extensions/v1beta1"},
+	"replicationcontrollers":   {"/api/v1"},
+	"resourcequotas":           {"/api/v1"},
+	"scheduledjobs":            {"/apis/batch/v2alpha1"},
+	"services":                 {"/api/v1"},
+	"statefulsets":             {"/apis/apps/v1", "/apis/apps/v1beta1"},
+	"storageclasses":           {"/apis/storage.k8s.io/v1", "/apis/storage.k8s.io/v1beta1"},
+}
+
+// notServedError is returned when none of the API paths of a kind is served
+// by the cluster.
+type notServedError string
+
+func (e notServedError) Error() string {
+	return fmt.Sprintf("%s are not served", string(e))
+}
+
+// decodedKinds are the kinds of objects that some part of the report
+// decodes in whole.  They are always listed in whole, so that one list
+// serves both those parts and the parts that need only their metadata.
+var decodedKinds = map[string]bool{
+	"daemonsets":             true,
+	"nodes":                  true,
+	"persistentvolumeclaims": true,
+	"pods":                   true,
+	"services":               true,
+	"storageclasses":         true,
+}
+
+// countedKinds are the kinds of namespaced objects that are counted per
+// namespace.  Secrets are deliberately not counted: we do not want to read
+// them at all.
+var countedKinds = []string{
+	"configmaps",
+	"daemonsets",
+	"deployments",
+	"jobs",
+	"persistentvolumeclaims",
+	"pods",
+	"replicasets",
+	"replicationcontrollers",
+	"services",
+}
+
+// listPageSize is the number of objects that are listed per request.
+const listPageSize = "500"
+
+// metadataOnlyAccept asks for only the metadata of listed objects, if the
+// server supports it, and for the whole objects otherwise.
+const metadataOnlyAccept = "application/json;as=PartialObjectMetadataList;g=meta.k8s.io;v=v1,application/json"
+
+// lifetimeKinds are the kinds of objects whose lifetimes are measured.
+var lifetimeKinds = []string{
+	"deployments",
+	"namespaces",
+	"pods",
+	"services",
+}
+
+// listCache keeps the objects that are listed for a report, so that each
+// kind is listed only once even though several parts of the report need it.
+type listCache interface {
+	// ClearListCache forgets the listed objects, so that the next report
+	// lists them again.
+	ClearListCache()
+}
+
 type serverVersioner interface {
 	ServerVersion() (string, error)
 }
 
-func nodeFromKubeNode(kn *kv1.Node) report.Node {
+type apiGroupLister interface {
+	ListAPIGroups() ([]report.APIGroup, error)
+}
+
+func nodeFromKubeNode(kn *kv1.Node, taints []kv1.Taint, rules cloudProviderRules) report.Node {
 	n := report.Node{
 		ID:                      getID(kn),
 		OperatingSystem:         strPtr(kn.Status.NodeInfo.OperatingSystem),
@@ -70,22 +144,84 @@ func nodeFromKubeNode(kn *kv1.Node) report.Node {
 		Architecture:            strPtr(kn.Status.NodeInfo.Architecture),
 		ContainerRuntimeVersion: strPtr(kn.Status.NodeInfo.ContainerRuntimeVersion),
 		KubeletVersion:          strPtr(kn.Status.NodeInfo.KubeletVersion),
-		CloudProvider:           strPtr(providerName(kn.Spec.ProviderID)),
+		CloudProvider:           strPtr(providerName(rules, kn.Spec.ProviderID, kn.Labels, kn.Annotations)),
+		Capacity:                resourcesFromKubeResourceList(kn.Status.Capacity),
+		Allocatable:             resourcesFromKubeResourceList(kn.Status.Allocatable),
+		Unschedulable:           boolPtr(kn.Spec.Unschedulable),
+		Taints:                  taintSummaries(taints),
 	}
+	for _, c := range kn.Status.Conditions {
+		n.Conditions = append(n.Conditions, report.NodeCondition{
+			Type:   string(c.Type),
+			Status: string(c.Status),
+		})
+	}
+	// We want to report the conditions in a deterministic order.
+	sort.Sort(conditionsByType(n.Conditions))
+	setTopology(&n, kn)
+	setVersions(&n)
+	return n
+}
+
+func resourcesFromKubeResourceList(rl kv1.ResourceList) []report.Resource {
+	var resources []report.Resource
 	// We want to iterate the resources in a deterministic order.
 	keys := []string{}
-	for k, _ := range kn.Status.Capacity {
+	for k, _ := range rl {
 		keys = append(keys, string(k))
 	}
 	sort.Strings(keys)
 	for _, k := range keys {
-		v := kn.Status.Capacity[kv1.ResourceName(k)]
-		n.Capacity = append(n.Capacity, report.Resource{
+		v := rl[kv1.ResourceName(k)]
+		resources = append(resources, report.Resource{
 			Resource: string(k),
 			Value:    v.String(),
 		})
 	}
-	return n
+	return resources
+}
+
+// taintSummaries counts a node's taints by effect.  Taint keys can be
+// anything, so they are hashed, and values are not reported at all.
+func taintSummaries(taints []kv1.Taint) []report.TaintSummary {
+	if len(taints) == 0 {
+		return nil
+	}
+	byEffect := map[string][]string{}
+	for _, t := range taints {
+		byEffect[string(t.Effect)] = append(byEffect[string(t.Effect)], hashOf(t.Key))
+	}
+	// We want to iterate the effects in a deterministic order.
+	effects := []string{}
+	for e := range byEffect {
+		effects = append(effects, e)
+	}
+	sort.Strings(effects)
+
+	var summaries []report.TaintSummary
+	for _, e := range effects {
+		hashes := byEffect[e]
+		sort.Strings(hashes)
+		summaries = append(summaries, report.TaintSummary{
+			Effect:    e,
+			Count:     int64(len(hashes)),
+			KeyHashes: hashes,
+		})
+	}
+	return summaries
+}
+
+func apiGroupFromKubeAPIGroup(kg *kunversioned.APIGroup) report.APIGroup {
+	g := report.APIGroup{
+		Name:             kg.Name,
+		PreferredVersion: strPtr(kg.PreferredVersion.Version),
+	}
+	// Versions are kept in the order the server reports them, which is
+	// its order of preference.
+	for _, v := range kg.Versions {
+		g.Versions = append(g.Versions, v.Version)
+	}
+	return g
 }
 
 func getID(kn *kv1.Node) string {
@@ -111,16 +247,10 @@ func strPtr(str string) *string {
 	return p
 }
 
-// providerName extracts the cloud provider name from a given
-// string that should match: <ProviderName>://<ProviderSpecficNodeID>
-// (see https://github.com/kubernetes/client-go/blob/v1.5.1/1.5/pkg/api/v1/types.go#L2446).
-// If the given string does not match this format, we return "unknown".
-func providerName(providerID string) string {
-	parts := strings.Split(providerID, "://")
-	if len(parts) == 2 && cloudProviders[parts[0]] {
-		return parts[0]
-	}
-	return "unknown"
+func boolPtr(b bool) *bool {
+	p := new(bool)
+	*p = b
+	return p
 }
 
 func newKubeClientWrapper() (*kubeClientWrapper, error) {
@@ -132,22 +262,36 @@ func newKubeClientWrapper() (*kubeClientWrapper, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &kubeClientWrapper{client: kubeClient}, nil
+	return &kubeClientWrapper{client: kubeClient, cloudProviderRules: defaultCloudProviderRules}, nil
 }
 
 type kubeClientWrapper struct {
 	client *kclient.Clientset
+	// cloudProviderRules are the rules for detecting the cloud provider of
+	// nodes.
+	cloudProviderRules cloudProviderRules
+	// listed are the objects listed since the cache was last cleared, by
+	// kind, namespace and whether only their metadata were listed.
+	listed map[string][]json.RawMessage
+}
+
+func (k *kubeClientWrapper) ClearListCache() {
+	k.listed = nil
 }
 
 func (k *kubeClientWrapper) ListNodes() ([]report.Node, error) {
-	knl, err := k.client.Core().Nodes().List(kapi.ListOptions{})
+	items, err := k.listItems("nodes", kapi.NamespaceAll, false)
 	if err != nil {
 		return nil, err
 	}
-	nodes := make([]report.Node, len(knl.Items))
-	for i := range knl.Items {
-		kn := &knl.Items[i]
-		nodes[i] = nodeFromKubeNode(kn)
+	nodes := make([]report.Node, len(items))
+	for i, item := range items {
+		var kn kv1.Node
+		taints, err := decodeKubeNode(item, &kn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode nodes: %v", err)
+		}
+		nodes[i] = nodeFromKubeNode(&kn, taints, k.cloudProviderRules)
 	}
 	return nodes, nil
 }
@@ -159,3 +303,629 @@ func (k *kubeClientWrapper) ServerVersion() (string, error) {
 	}
 	return info.String(), nil
 }
+
+func (k *kubeClientWrapper) ListAPIGroups() ([]report.APIGroup, error) {
+	kgl, err := k.client.Discovery().ServerGroups()
+	if err != nil {
+		return nil, err
+	}
+	groups := []report.APIGroup{}
+	for i := range kgl.Groups {
+		kg := &kgl.Groups[i]
+		// Discovery returns an empty core group if /api could not be read.
+		if len(kg.Versions) == 0 {
+			continue
+		}
+		groups = append(groups, apiGroupFromKubeAPIGroup(kg))
+	}
+	// We want to report the groups in a deterministic order.
+	sort.Sort(apiGroupsByName(groups))
+	return groups, nil
+}
+
+func (k *kubeClientWrapper) ListSystemWorkloads() ([]string, error) {
+	names := []string{}
+	for _, kind := range []string{"daemonsets", "deployments"} {
+		objs, err := k.listObjectMeta(kind, kapi.NamespaceSystem)
+		if err != nil {
+			return nil, err
+		}
+		for i := range objs {
+			names = append(names, objs[i].Name)
+		}
+	}
+	return names, nil
+}
+
+func (k *kubeClientWrapper) ListNamespaces() ([]namespaceCounts, error) {
+	namespaces, err := k.listObjectMeta("namespaces", kapi.NamespaceAll)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]namespaceCounts, len(namespaces))
+	for i := range namespaces {
+		byName[namespaces[i].Name] = namespaceCounts{}
+	}
+	for _, kind := range countedKinds {
+		objs, err := k.listObjectMeta(kind, kapi.NamespaceAll)
+		if err != nil {
+			return nil, err
+		}
+		// Empty namespaces are counted too.
+		for _, c := range byName {
+			c[kind] = 0
+		}
+		for i := range objs {
+			if c, found := byName[objs[i].Namespace]; found {
+				c[kind]++
+			}
+		}
+	}
+	counts := make([]namespaceCounts, 0, len(byName))
+	for _, c := range byName {
+		counts = append(counts, c)
+	}
+	return counts, nil
+}
+
+func (k *kubeClientWrapper) ListObjects() (map[string][]objectTimes, error) {
+	objects := map[string][]objectTimes{}
+	for _, kind := range lifetimeKinds {
+		objs, err := k.listObjectMeta(kind, kapi.NamespaceAll)
+		if err != nil {
+			return nil, err
+		}
+		times := make([]objectTimes, len(objs))
+		for i := range objs {
+			times[i] = objectTimes{
+				uid:     string(objs[i].UID),
+				created: objs[i].CreationTimestamp.Time,
+			}
+		}
+		objects[kind] = times
+	}
+	return objects, nil
+}
+
+func (k *kubeClientWrapper) ListStorage() (storageObjects, error) {
+	var objs storageObjects
+
+	// StorageClasses are listed by path, because this client only knows
+	// storage/v1beta1, which is not served by newer versions of kubernetes.
+	items, err := k.listItems("storageclasses", kapi.NamespaceAll, false)
+	if err != nil {
+		return storageObjects{}, err
+	}
+	for _, item := range items {
+		var ksc kstorage.StorageClass
+		if err := json.Unmarshal(item, &ksc); err != nil {
+			return storageObjects{}, fmt.Errorf("failed to decode storageclasses: %v", err)
+		}
+		objs.classes = append(objs.classes, storageClassFromKubeStorageClass(&ksc))
+	}
+
+	kpvl, err := k.client.Core().PersistentVolumes().List(kapi.ListOptions{})
+	if err != nil {
+		return storageObjects{}, err
+	}
+	for i := range kpvl.Items {
+		spec := &kpvl.Items[i].Spec
+		objs.volumes = append(objs.volumes, volume{
+			accessModes:   accessModeNames(spec.AccessModes),
+			reclaimPolicy: string(spec.PersistentVolumeReclaimPolicy),
+			bytes:         storageBytes(spec.Capacity),
+		})
+	}
+
+	items, err = k.listItems("persistentvolumeclaims", kapi.NamespaceAll, false)
+	if err != nil {
+		return storageObjects{}, err
+	}
+	for _, item := range items {
+		var kpvc kv1.PersistentVolumeClaim
+		if err := json.Unmarshal(item, &kpvc); err != nil {
+			return storageObjects{}, fmt.Errorf("failed to decode persistentvolumeclaims: %v", err)
+		}
+		spec := &kpvc.Spec
+		objs.claims = append(objs.claims, volume{
+			accessModes: accessModeNames(spec.AccessModes),
+			bytes:       storageBytes(spec.Resources.Requests),
+		})
+	}
+
+	return objs, nil
+}
+
+// ListNetwork lists the network objects.  Services and nodes are decoded
+// from their JSON, because this client's types lack the fields of
+// dual-stack clusters.
+func (k *kubeClientWrapper) ListNetwork() (networkObjects, error) {
+	var objs networkObjects
+
+	items, err := k.listItems("services", kapi.NamespaceAll, false)
+	if err != nil {
+		return networkObjects{}, err
+	}
+	if objs.services, err = servicesFromKubeJSON(items); err != nil {
+		return networkObjects{}, fmt.Errorf("failed to decode services: %v", err)
+	}
+
+	items, err = k.listItems("nodes", kapi.NamespaceAll, false)
+	if err != nil {
+		return networkObjects{}, err
+	}
+	if objs.podCIDRs, err = podCIDRsFromKubeJSON(items); err != nil {
+		return networkObjects{}, fmt.Errorf("failed to decode nodes: %v", err)
+	}
+
+	items, err = k.listItems("daemonsets", kapi.NamespaceSystem, false)
+	if err != nil {
+		return networkObjects{}, err
+	}
+	for _, item := range items {
+		var kds struct {
+			Metadata kv1.ObjectMeta `json:"metadata"`
+			Spec     struct {
+				Template struct {
+					Spec kv1.PodSpec `json:"spec"`
+				} `json:"template"`
+			} `json:"spec"`
+		}
+		if err := json.Unmarshal(item, &kds); err != nil {
+			return networkObjects{}, fmt.Errorf("failed to decode daemonsets: %v", err)
+		}
+		ds := daemonSet{name: kds.Metadata.Name}
+		for _, c := range kds.Spec.Template.Spec.Containers {
+			ds.args = append(ds.args, c.Command...)
+			ds.args = append(ds.args, c.Args...)
+		}
+		if ds.name == kubeProxyName {
+			ds.configs = k.mountedConfigs(&kds.Spec.Template.Spec)
+		}
+		objs.daemonSets = append(objs.daemonSets, ds)
+	}
+
+	return objs, nil
+}
+
+// mountedConfigs returns the data of the ConfigMaps in kube-system that a
+// pod spec mounts.  ConfigMaps that can not be read are left out.
+func (k *kubeClientWrapper) mountedConfigs(spec *kv1.PodSpec) []string {
+	var configs []string
+	for i := range spec.Volumes {
+		cmv := spec.Volumes[i].ConfigMap
+		if cmv == nil {
+			continue
+		}
+		kcm, err := k.client.Core().ConfigMaps(kapi.NamespaceSystem).Get(cmv.Name)
+		if err != nil {
+			continue
+		}
+		// We want to read the data in a deterministic order.
+		keys := []string{}
+		for key := range kcm.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			configs = append(configs, kcm.Data[key])
+		}
+	}
+	return configs
+}
+
+func (k *kubeClientWrapper) ListDistributionSignals() (distributionSignals, error) {
+	items, err := k.listItems("nodes", kapi.NamespaceAll, false)
+	if err != nil {
+		return distributionSignals{}, err
+	}
+	knodes := make([]kv1.Node, len(items))
+	for i, item := range items {
+		if err := json.Unmarshal(item, &knodes[i]); err != nil {
+			return distributionSignals{}, fmt.Errorf("failed to decode nodes: %v", err)
+		}
+	}
+	namespaces, err := k.listObjectMeta("namespaces", kapi.NamespaceAll)
+	if err != nil {
+		return distributionSignals{}, err
+	}
+	return distributionSignalsFromKube(knodes, namespaces), nil
+}
+
+func (k *kubeClientWrapper) ListExtensionConfigMaps(selector string) ([]extensionConfigMap, error) {
+	ksel, err := klabels.Parse(selector)
+	if err != nil {
+		return nil, err
+	}
+	kcl, err := k.client.Core().ConfigMaps(kapi.NamespaceAll).List(kapi.ListOptions{LabelSelector: ksel})
+	if err != nil {
+		return nil, err
+	}
+	return extensionConfigMapsFromKube(kcl.Items), nil
+}
+
+func (k *kubeClientWrapper) SystemNamespaceAnnotations() (map[string]string, error) {
+	kn, err := k.client.Core().Namespaces().Get(kapi.NamespaceSystem)
+	if err != nil {
+		return nil, err
+	}
+	return kn.Annotations, nil
+}
+
+func (k *kubeClientWrapper) ListPods() (podObjects, error) {
+	items, err := k.listItems("pods", kapi.NamespaceAll, false)
+	if err != nil {
+		return podObjects{}, err
+	}
+	kpods := make([]kv1.Pod, len(items))
+	for i, item := range items {
+		if err := decodeKubePod(item, &kpods[i]); err != nil {
+			return podObjects{}, fmt.Errorf("failed to decode pods: %v", err)
+		}
+	}
+	return podObjectsFromKubePods(kpods), nil
+}
+
+func (k *kubeClientWrapper) CountWorkloadFeatures() (map[string]int64, error) {
+	counts := map[string]int64{}
+	var failures []string
+	for _, fk := range featureKinds {
+		for _, kind := range append([]string{fk.kind}, fk.oldNames...) {
+			items, err := k.listItems(kind, kapi.NamespaceAll, true)
+			if _, notServed := err.(notServedError); notServed {
+				continue
+			}
+			if err != nil {
+				failures = append(failures, err.Error())
+			} else {
+				counts[fk.kind] = int64(len(items))
+			}
+			break
+		}
+	}
+	if len(failures) > 0 {
+		return counts, errors.New(strings.Join(failures, "; "))
+	}
+	return counts, nil
+}
+
+// listObjectMeta lists all objects of a kind in a namespace, or across all
+// namespaces if namespace is empty, decoding only their metadata.  That is
+// all we need, and it works the same way for every kind.
+func (k *kubeClientWrapper) listObjectMeta(kind, namespace string) ([]kv1.ObjectMeta, error) {
+	items, err := k.listItems(kind, namespace, true)
+	if err != nil {
+		return nil, err
+	}
+	metas := make([]kv1.ObjectMeta, len(items))
+	for i, item := range items {
+		var obj struct {
+			Metadata kv1.ObjectMeta `json:"metadata"`
+		}
+		if err := json.Unmarshal(item, &obj); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %v", kind, err)
+		}
+		metas[i] = obj.Metadata
+	}
+	return metas, nil
+}
+
+// listItems lists all objects of a kind in a namespace, or across all
+// namespaces if namespace is empty, without decoding them.  Each of the
+// kind's API paths is tried in turn until one is served.  Objects are only
+// listed once until the cache is cleared, and the objects of a namespace are
+// taken from those of all namespaces if they were listed.
+func (k *kubeClientWrapper) listItems(kind, namespace string, metadataOnly bool) ([]json.RawMessage, error) {
+	if decodedKinds[kind] {
+		metadataOnly = false
+	}
+	if items, found := k.listed[listKey(kind, namespace, metadataOnly)]; found {
+		return items, nil
+	}
+	var items []json.RawMessage
+	var err error
+	if all, found := k.listed[listKey(kind, kapi.NamespaceAll, metadataOnly)]; found {
+		if items, err = inNamespace(all, namespace); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %v", kind, err)
+		}
+	} else if items, err = k.listUncached(kind, namespace, metadataOnly); err != nil {
+		return nil, err
+	}
+	if k.listed == nil {
+		k.listed = map[string][]json.RawMessage{}
+	}
+	k.listed[listKey(kind, namespace, metadataOnly)] = items
+	return items, nil
+}
+
+// listKey is the key of the objects in the cache of a kubeClientWrapper.
+func listKey(kind, namespace string, metadataOnly bool) string {
+	return fmt.Sprintf("%s/%s/%t", kind, namespace, metadataOnly)
+}
+
+// listUncached lists objects like listItems, but does not use the cache.
+func (k *kubeClientWrapper) listUncached(kind, namespace string, metadataOnly bool) ([]json.RawMessage, error) {
+	paths := listPaths(kind, namespace)
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("failed to list %s: unknown kind", kind)
+	}
+	for _, path := range paths {
+		items, err := k.listPages(path, metadataOnly)
+		if kerrors.IsNotFound(err) {
+			// Not served by this version of kubernetes; try the next.
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %v", kind, err)
+		}
+		return items, nil
+	}
+	return nil, notServedError(kind)
+}
+
+// listPages lists the objects at an API path a page at a time, so that large
+// clusters are not read in a single response.  If metadataOnly is set, the
+// server is asked to send only the metadata of the objects.
+func (k *kubeClientWrapper) listPages(path string, metadataOnly bool) ([]json.RawMessage, error) {
+	var items []json.RawMessage
+	cont := ""
+	for {
+		req := k.client.Core().GetRESTClient().Get().AbsPath(path).Param("limit", listPageSize)
+		if cont != "" {
+			req = req.Param("continue", cont)
+		}
+		if metadataOnly {
+			req = req.SetHeader("Accept", metadataOnlyAccept)
+		}
+		body, err := req.Do().Raw()
+		if err != nil {
+			return nil, err
+		}
+		var list struct {
+			Metadata struct {
+				Continue string `json:"continue"`
+			} `json:"metadata"`
+			Items []json.RawMessage `json:"items"`
+		}
+		if err := json.Unmarshal(body, &list); err != nil {
+			return nil, err
+		}
+		items = append(items, list.Items...)
+		if list.Metadata.Continue == "" {
+			return items, nil
+		}
+		cont = list.Metadata.Continue
+	}
+}
+
+// inNamespace returns the objects that are in a namespace.
+func inNamespace(items []json.RawMessage, namespace string) ([]json.RawMessage, error) {
+	var in []json.RawMessage
+	for _, item := range items {
+		var obj struct {
+			Metadata kv1.ObjectMeta `json:"metadata"`
+		}
+		if err := json.Unmarshal(item, &obj); err != nil {
+			return nil, err
+		}
+		if obj.Metadata.Namespace == namespace {
+			in = append(in, item)
+		}
+	}
+	return in, nil
+}
+
+// listPaths returns the API paths that list a kind of object in a namespace,
+// or across all namespaces if namespace is empty, in order of preference.
+func listPaths(kind, namespace string) []string {
+	var paths []string
+	for _, gv := range kindGroupVersions[kind] {
+		if namespace != "" {
+			gv += "/namespaces/" + namespace
+		}
+		paths = append(paths, gv+"/"+kind)
+	}
+	return paths
+}
+
+// servicesFromKubeJSON decodes the type and cluster IPs of services.  Older
+// versions of kubernetes only set the single clusterIP.
+func servicesFromKubeJSON(items []json.RawMessage) ([]service, error) {
+	var services []service
+	for _, item := range items {
+		var ks struct {
+			Spec struct {
+				Type       string   `json:"type"`
+				ClusterIP  string   `json:"clusterIP"`
+				ClusterIPs []string `json:"clusterIPs"`
+			} `json:"spec"`
+		}
+		if err := json.Unmarshal(item, &ks); err != nil {
+			return nil, err
+		}
+		s := service{serviceType: ks.Spec.Type, clusterIPs: ks.Spec.ClusterIPs}
+		if len(s.clusterIPs) == 0 && ks.Spec.ClusterIP != "" {
+			s.clusterIPs = []string{ks.Spec.ClusterIP}
+		}
+		services = append(services, s)
+	}
+	return services, nil
+}
+
+// podCIDRsFromKubeJSON decodes the pod CIDRs of nodes.  Older versions of
+// kubernetes only set the single podCIDR.
+func podCIDRsFromKubeJSON(items []json.RawMessage) ([]string, error) {
+	var cidrs []string
+	for _, item := range items {
+		var kn struct {
+			Spec struct {
+				PodCIDR  string   `json:"podCIDR"`
+				PodCIDRs []string `json:"podCIDRs"`
+			} `json:"spec"`
+		}
+		if err := json.Unmarshal(item, &kn); err != nil {
+			return nil, err
+		}
+		if len(kn.Spec.PodCIDRs) > 0 {
+			cidrs = append(cidrs, kn.Spec.PodCIDRs...)
+		} else if kn.Spec.PodCIDR != "" {
+			cidrs = append(cidrs, kn.Spec.PodCIDR)
+		}
+	}
+	return cidrs, nil
+}
+
+// distributionSignalsFromKube collects the signals that distributions are
+// detected from.
+func distributionSignalsFromKube(knodes []kv1.Node, namespaces []kv1.ObjectMeta) distributionSignals {
+	signals := distributionSignals{}
+	for i := range knodes {
+		kn := &knodes[i]
+		if len(kn.Labels) > 0 {
+			signals.nodeLabels = append(signals.nodeLabels, kn.Labels)
+		}
+		if kn.Spec.ProviderID != "" {
+			signals.providerIDs = append(signals.providerIDs, kn.Spec.ProviderID)
+		}
+	}
+	for i := range namespaces {
+		signals.namespaces = append(signals.namespaces, namespaces[i].Name)
+	}
+	return signals
+}
+
+// extensionConfigMapsFromKube returns the names and data of ConfigMaps.
+func extensionConfigMapsFromKube(kcms []kv1.ConfigMap) []extensionConfigMap {
+	cms := []extensionConfigMap{}
+	for i := range kcms {
+		kcm := &kcms[i]
+		cms = append(cms, extensionConfigMap{name: kcm.Namespace + "/" + kcm.Name, data: kcm.Data})
+	}
+	return cms
+}
+
+// validLabelSelector checks that a label selector can be parsed.
+func validLabelSelector(selector string) error {
+	_, err := klabels.Parse(selector)
+	return err
+}
+
+// decodeKubeNode decodes a node, and returns its taints.  This client's
+// types only read them from the annotation that older versions of
+// kubernetes used, so they are decoded from the spec here, and the
+// annotation is only read if the spec has none.
+func decodeKubeNode(data []byte, kn *kv1.Node) ([]kv1.Taint, error) {
+	if err := json.Unmarshal(data, kn); err != nil {
+		return nil, err
+	}
+	var node struct {
+		Spec struct {
+			Taints []kv1.Taint `json:"taints"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	if len(node.Spec.Taints) > 0 {
+		return node.Spec.Taints, nil
+	}
+	value := kn.Annotations[kapi.TaintsAnnotationKey]
+	if value == "" {
+		return nil, nil
+	}
+	var taints []kv1.Taint
+	if err := json.Unmarshal([]byte(value), &taints); err != nil {
+		// An annotation that can not be parsed is ignored, as the server
+		// would.
+		return nil, nil
+	}
+	return taints, nil
+}
+
+// decodeKubePod decodes a pod, including its init containers.  This
+// client's types only read them from the annotations that older versions of
+// kubernetes used, so they are decoded from the spec here.
+func decodeKubePod(data []byte, kp *kv1.Pod) error {
+	if err := json.Unmarshal(data, kp); err != nil {
+		return err
+	}
+	var pod struct {
+		Spec struct {
+			InitContainers []kv1.Container `json:"initContainers"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(data, &pod); err != nil {
+		return err
+	}
+	kp.Spec.InitContainers = pod.Spec.InitContainers
+	if len(kp.Spec.InitContainers) > 0 {
+		return nil
+	}
+	for _, key := range []string{kv1.PodInitContainersBetaAnnotationKey, kv1.PodInitContainersAnnotationKey} {
+		if value := kp.Annotations[key]; value != "" {
+			// An annotation that can not be parsed is ignored, as the
+			// server would.
+			json.Unmarshal([]byte(value), &kp.Spec.InitContainers)
+			break
+		}
+	}
+	return nil
+}
+
+// imagesFromKubePods returns the image of every container, including init
+// containers, of the pods.
+func imagesFromKubePods(kpods []kv1.Pod) []string {
+	images := []string{}
+	for i := range kpods {
+		for _, c := range allContainers(&kpods[i]) {
+			images = append(images, c.Image)
+		}
+	}
+	return images
+}
+
+// defaultStorageClassAnnotation marks the default StorageClass.
+const defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+
+// betaDefaultStorageClassAnnotation marks the default StorageClass in older
+// versions of kubernetes.
+const betaDefaultStorageClassAnnotation = "storageclass.beta.kubernetes.io/is-default-class"
+
+func storageClassFromKubeStorageClass(ksc *kstorage.StorageClass) storageClass {
+	isDefault, found := ksc.Annotations[defaultStorageClassAnnotation]
+	if !found {
+		isDefault = ksc.Annotations[betaDefaultStorageClassAnnotation]
+	}
+	return storageClass{
+		provisioner: ksc.Provisioner,
+		isDefault:   isDefault == "true",
+	}
+}
+
+func accessModeNames(modes []kv1.PersistentVolumeAccessMode) []string {
+	names := make([]string, len(modes))
+	for i, m := range modes {
+		names[i] = string(m)
+	}
+	return names
+}
+
+func storageBytes(rl kv1.ResourceList) int64 {
+	q, found := rl[kv1.ResourceStorage]
+	if !found {
+		return 0
+	}
+	return q.Value()
+}
+
+type apiGroupsByName []report.APIGroup
+
+func (s apiGroupsByName) Len() int           { return len(s) }
+func (s apiGroupsByName) Less(i, j int) bool { return s[i].Name < s[j].Name }
+func (s apiGroupsByName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+type conditionsByType []report.NodeCondition
+
+func (s conditionsByType) Len() int           { return len(s) }
+func (s conditionsByType) Less(i, j int) bool { return s[i].Type < s[j].Type }
+func (s conditionsByType) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
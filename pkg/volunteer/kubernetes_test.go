@@ -17,24 +17,35 @@ limitations under the License.
 package volunteer
 
 import (
+	"encoding/json"
 	"reflect"
+	"sort"
 	"testing"
 
 	"github.com/kubernetes-incubator/spartakus/pkg/report"
 	"github.com/kylelemons/godebug/pretty"
 	kresource "k8s.io/client-go/1.5/pkg/api/resource"
+	kunversioned "k8s.io/client-go/1.5/pkg/api/unversioned"
 	kv1 "k8s.io/client-go/1.5/pkg/api/v1"
+	kstorage "k8s.io/client-go/1.5/pkg/apis/storage/v1beta1"
 )
 
+func sortedStrings(strs ...string) []string {
+	sort.Strings(strs)
+	return strs
+}
+
 func TestNodeFromKubeNode(t *testing.T) {
 	testCases := []struct {
 		input  kv1.Node
+		taints []kv1.Taint
 		expect report.Node
 	}{
 		{
 			input: kv1.Node{},
 			expect: report.Node{
 				CloudProvider: strPtr("unknown"),
+				Unschedulable: boolPtr(false),
 			},
 		},
 		{
@@ -45,6 +56,7 @@ func TestNodeFromKubeNode(t *testing.T) {
 			},
 			expect: report.Node{
 				CloudProvider: strPtr("unknown"),
+				Unschedulable: boolPtr(false),
 			},
 		},
 		{
@@ -68,6 +80,7 @@ func TestNodeFromKubeNode(t *testing.T) {
 					{Resource: "r3", Value: "300"},
 				},
 				CloudProvider: strPtr("unknown"),
+				Unschedulable: boolPtr(false),
 			},
 		},
 		{
@@ -94,6 +107,7 @@ func TestNodeFromKubeNode(t *testing.T) {
 				ContainerRuntimeVersion: strPtr("runtime"),
 				KubeletVersion:          strPtr("kubelet"),
 				CloudProvider:           strPtr("unknown"),
+				Unschedulable:           boolPtr(false),
 			},
 		},
 		{
@@ -102,8 +116,55 @@ func TestNodeFromKubeNode(t *testing.T) {
 					ProviderID: "foo://bar",
 				},
 			},
+			expect: report.Node{
+				CloudProvider: strPtr(hashOf("foo")),
+				Unschedulable: boolPtr(false),
+			},
+		},
+		{
+			input: kv1.Node{
+				Spec: kv1.NodeSpec{
+					Unschedulable: true,
+				},
+				Status: kv1.NodeStatus{
+					Allocatable: kv1.ResourceList{
+						"r2": kresource.MustParse("20"),
+						"r1": kresource.MustParse("10"),
+					},
+					Conditions: []kv1.NodeCondition{
+						{Type: kv1.NodeReady, Status: kv1.ConditionTrue},
+						{Type: kv1.NodeDiskPressure, Status: kv1.ConditionUnknown},
+					},
+				},
+			},
+			taints: []kv1.Taint{
+				{Key: "dedicated", Value: "db", Effect: kv1.TaintEffectNoSchedule},
+				{Key: "example.com/gpu", Effect: kv1.TaintEffectPreferNoSchedule},
+				{Key: "example.com/ssd", Effect: kv1.TaintEffectNoSchedule},
+			},
 			expect: report.Node{
 				CloudProvider: strPtr("unknown"),
+				Allocatable: []report.Resource{
+					{Resource: "r1", Value: "10"},
+					{Resource: "r2", Value: "20"},
+				},
+				Conditions: []report.NodeCondition{
+					{Type: "DiskPressure", Status: "Unknown"},
+					{Type: "Ready", Status: "True"},
+				},
+				Unschedulable: boolPtr(true),
+				Taints: []report.TaintSummary{
+					{
+						Effect:    "NoSchedule",
+						Count:     2,
+						KeyHashes: sortedStrings(hashOf("dedicated"), hashOf("example.com/ssd")),
+					},
+					{
+						Effect:    "PreferNoSchedule",
+						Count:     1,
+						KeyHashes: []string{hashOf("example.com/gpu")},
+					},
+				},
 			},
 		},
 		{
@@ -114,12 +175,13 @@ func TestNodeFromKubeNode(t *testing.T) {
 			},
 			expect: report.Node{
 				CloudProvider: strPtr("aws"),
+				Unschedulable: boolPtr(false),
 			},
 		},
 	}
 
 	for i, tc := range testCases {
-		n := nodeFromKubeNode(&tc.input)
+		n := nodeFromKubeNode(&tc.input, tc.taints, defaultCloudProviderRules)
 		if n.ID == "" || n.ID == tc.input.Name {
 			t.Errorf("[%d] expected anonymized ID, got %q", i, n.ID)
 		}
@@ -129,3 +191,403 @@ func TestNodeFromKubeNode(t *testing.T) {
 		}
 	}
 }
+
+func TestAPIGroupFromKubeAPIGroup(t *testing.T) {
+	testCases := []struct {
+		input  kunversioned.APIGroup
+		expect report.APIGroup
+	}{
+		{
+			input:  kunversioned.APIGroup{},
+			expect: report.APIGroup{},
+		},
+		{
+			input: kunversioned.APIGroup{
+				Name: "batch",
+				Versions: []kunversioned.GroupVersionForDiscovery{
+					{GroupVersion: "batch/v1", Version: "v1"},
+					{GroupVersion: "batch/v2alpha1", Version: "v2alpha1"},
+				},
+				PreferredVersion: kunversioned.GroupVersionForDiscovery{GroupVersion: "batch/v1", Version: "v1"},
+			},
+			expect: report.APIGroup{
+				Name:             "batch",
+				Versions:         []string{"v1", "v2alpha1"},
+				PreferredVersion: strPtr("v1"),
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		g := apiGroupFromKubeAPIGroup(&tc.input)
+		if !reflect.DeepEqual(g, tc.expect) {
+			t.Errorf("[%d]: did not get expected result:\n%s", i, pretty.Compare(g, tc.expect))
+		}
+	}
+}
+
+func TestStorageClassFromKubeStorageClass(t *testing.T) {
+	testCases := []struct {
+		input  kstorage.StorageClass
+		expect storageClass
+	}{
+		{
+			input:  kstorage.StorageClass{Provisioner: "kubernetes.io/aws-ebs"},
+			expect: storageClass{provisioner: "kubernetes.io/aws-ebs"},
+		},
+		{
+			input: kstorage.StorageClass{
+				ObjectMeta:  kv1.ObjectMeta{Annotations: map[string]string{defaultStorageClassAnnotation: "true"}},
+				Provisioner: "kubernetes.io/aws-ebs",
+			},
+			expect: storageClass{provisioner: "kubernetes.io/aws-ebs", isDefault: true},
+		},
+		{
+			input: kstorage.StorageClass{
+				ObjectMeta:  kv1.ObjectMeta{Annotations: map[string]string{defaultStorageClassAnnotation: "false"}},
+				Provisioner: "kubernetes.io/aws-ebs",
+			},
+			expect: storageClass{provisioner: "kubernetes.io/aws-ebs"},
+		},
+		{
+			input: kstorage.StorageClass{
+				ObjectMeta:  kv1.ObjectMeta{Annotations: map[string]string{betaDefaultStorageClassAnnotation: "true"}},
+				Provisioner: "kubernetes.io/gce-pd",
+			},
+			expect: storageClass{provisioner: "kubernetes.io/gce-pd", isDefault: true},
+		},
+		{ // The GA annotation wins over the beta one.
+			input: kstorage.StorageClass{
+				ObjectMeta: kv1.ObjectMeta{Annotations: map[string]string{
+					defaultStorageClassAnnotation:     "false",
+					betaDefaultStorageClassAnnotation: "true",
+				}},
+				Provisioner: "kubernetes.io/gce-pd",
+			},
+			expect: storageClass{provisioner: "kubernetes.io/gce-pd"},
+		},
+	}
+
+	for i, tc := range testCases {
+		sc := storageClassFromKubeStorageClass(&tc.input)
+		if !reflect.DeepEqual(sc, tc.expect) {
+			t.Errorf("[%d] expected %+v, got %+v", i, tc.expect, sc)
+		}
+	}
+}
+
+func TestStorageBytes(t *testing.T) {
+	testCases := []struct {
+		input  kv1.ResourceList
+		expect int64
+	}{
+		{input: nil, expect: 0},
+		{input: kv1.ResourceList{kv1.ResourceStorage: kresource.MustParse("1Gi")}, expect: 1 << 30},
+		{input: kv1.ResourceList{kv1.ResourceStorage: kresource.MustParse("500M")}, expect: 500000000},
+	}
+
+	for i, tc := range testCases {
+		if b := storageBytes(tc.input); b != tc.expect {
+			t.Errorf("[%d] expected %d, got %d", i, tc.expect, b)
+		}
+	}
+}
+
+func TestImagesFromKubePods(t *testing.T) {
+	testCases := []struct {
+		input  []kv1.Pod
+		expect []string
+	}{
+		{
+			input:  nil,
+			expect: []string{},
+		},
+		{
+			input:  []kv1.Pod{{}},
+			expect: []string{},
+		},
+		{
+			input: []kv1.Pod{
+				{Spec: kv1.PodSpec{Containers: []kv1.Container{
+					{Name: "a", Image: "nginx:1.11"},
+					{Name: "b", Image: "gcr.io/google_containers/pause-amd64:3.0"},
+				}}},
+				{Spec: kv1.PodSpec{Containers: []kv1.Container{
+					{Name: "a", Image: "nginx:1.11"},
+				}}},
+			},
+			expect: []string{"nginx:1.11", "gcr.io/google_containers/pause-amd64:3.0", "nginx:1.11"},
+		},
+		{ // init containers come first, as they run first
+			input: []kv1.Pod{
+				{Spec: kv1.PodSpec{
+					InitContainers: []kv1.Container{{Name: "init", Image: "busybox@sha256:abcd"}},
+					Containers:     []kv1.Container{{Name: "a", Image: "nginx:1.11"}},
+				}},
+			},
+			expect: []string{"busybox@sha256:abcd", "nginx:1.11"},
+		},
+	}
+
+	for i, tc := range testCases {
+		images := imagesFromKubePods(tc.input)
+		if !reflect.DeepEqual(images, tc.expect) {
+			t.Errorf("[%d] expected %v, got %v", i, tc.expect, images)
+		}
+	}
+}
+
+func TestDistributionSignalsFromKube(t *testing.T) {
+	nodes := []kv1.Node{
+		{
+			ObjectMeta: kv1.ObjectMeta{Name: "node1", Labels: map[string]string{"cloud.google.com/gke-nodepool": "default-pool"}},
+			Spec:       kv1.NodeSpec{ProviderID: "gce://project/us-central1-a/node1"},
+		},
+		{
+			ObjectMeta: kv1.ObjectMeta{Name: "node2"},
+		},
+	}
+	namespaces := []kv1.ObjectMeta{
+		{Name: "default"},
+		{Name: "kube-system"},
+	}
+	expect := distributionSignals{
+		nodeLabels:  []map[string]string{{"cloud.google.com/gke-nodepool": "default-pool"}},
+		providerIDs: []string{"gce://project/us-central1-a/node1"},
+		namespaces:  []string{"default", "kube-system"},
+	}
+
+	signals := distributionSignalsFromKube(nodes, namespaces)
+	if !reflect.DeepEqual(signals, expect) {
+		t.Errorf("did not get expected result:\n%s", pretty.Compare(signals, expect))
+	}
+}
+
+func TestExtensionConfigMapsFromKube(t *testing.T) {
+	kcms := []kv1.ConfigMap{
+		{
+			ObjectMeta: kv1.ObjectMeta{Namespace: "kube-system", Name: "spartakus"},
+			Data:       map[string]string{"env": "prod"},
+		},
+		{
+			ObjectMeta: kv1.ObjectMeta{Namespace: "team-a", Name: "facts"},
+		},
+	}
+	expect := []extensionConfigMap{
+		{name: "kube-system/spartakus", data: map[string]string{"env": "prod"}},
+		{name: "team-a/facts"},
+	}
+
+	cms := extensionConfigMapsFromKube(kcms)
+	if !reflect.DeepEqual(cms, expect) {
+		t.Errorf("did not get expected result:\n%s", pretty.Compare(cms, expect))
+	}
+}
+
+func TestListPaths(t *testing.T) {
+	testCases := []struct {
+		kind      string
+		namespace string
+		expect    []string
+	}{
+		{
+			kind:   "pods",
+			expect: []string{"/api/v1/pods"},
+		},
+		{
+			kind:   "deployments",
+			expect: []string{"/apis/apps/v1/deployments", "/apis/extensions/v1beta1/deployments"},
+		},
+		{
+			kind:      "daemonsets",
+			namespace: "kube-system",
+			expect:    []string{"/apis/apps/v1/namespaces/kube-system/daemonsets", "/apis/extensions/v1beta1/namespaces/kube-system/daemonsets"},
+		},
+		{
+			kind:   "storageclasses",
+			expect: []string{"/apis/storage.k8s.io/v1/storageclasses", "/apis/storage.k8s.io/v1beta1/storageclasses"},
+		},
+		{
+			kind:   "secrets",
+			expect: nil,
+		},
+	}
+
+	for i, tc := range testCases {
+		got := listPaths(tc.kind, tc.namespace)
+		if !reflect.DeepEqual(got, tc.expect) {
+			t.Errorf("[%d] did not get expected result:\n%s", i, pretty.Compare(got, tc.expect))
+		}
+	}
+}
+
+func TestListItemsCached(t *testing.T) {
+	pods := []json.RawMessage{
+		json.RawMessage(`{"metadata": {"namespace": "default", "name": "a"}}`),
+		json.RawMessage(`{"metadata": {"namespace": "kube-system", "name": "b"}}`),
+	}
+	// The wrapper has no client, so anything that is not cached fails.
+	k := &kubeClientWrapper{listed: map[string][]json.RawMessage{
+		listKey("pods", "", false): pods,
+	}}
+
+	testCases := []struct {
+		kind         string
+		namespace    string
+		metadataOnly bool
+		expect       []json.RawMessage
+	}{
+		{"pods", "", false, pods},
+		// Pods are always listed in whole, so a list of only their
+		// metadata is the same list.
+		{"pods", "", true, pods},
+		{"pods", "kube-system", true, pods[1:]},
+		{"pods", "other", false, nil},
+	}
+	for i, tc := range testCases {
+		items, err := k.listItems(tc.kind, tc.namespace, tc.metadataOnly)
+		if err != nil {
+			t.Errorf("[%d] unexpected error: %v", i, err)
+		} else if !reflect.DeepEqual(items, tc.expect) {
+			t.Errorf("[%d] expected %s, got %s", i, tc.expect, items)
+		}
+	}
+
+	k.ClearListCache()
+	if len(k.listed) != 0 {
+		t.Errorf("expected an empty cache, got %v", k.listed)
+	}
+}
+
+func TestDecodeKubePod(t *testing.T) {
+	testCases := []struct {
+		input  string
+		expect []string // names of the init containers
+	}{
+		{
+			input:  `{"metadata": {"name": "a"}, "spec": {"containers": [{"name": "c"}]}}`,
+			expect: nil,
+		},
+		{
+			input:  `{"spec": {"initContainers": [{"name": "i1"}, {"name": "i2"}], "containers": [{"name": "c"}]}}`,
+			expect: []string{"i1", "i2"},
+		},
+		{ // older versions of kubernetes only had an annotation
+			input:  `{"metadata": {"annotations": {"pod.beta.kubernetes.io/init-containers": "[{\"name\": \"i1\"}]"}}}`,
+			expect: []string{"i1"},
+		},
+		{ // an annotation that can not be parsed is ignored
+			input:  `{"metadata": {"annotations": {"pod.alpha.kubernetes.io/init-containers": "bogus"}}}`,
+			expect: nil,
+		},
+	}
+
+	for i, tc := range testCases {
+		var kp kv1.Pod
+		if err := decodeKubePod([]byte(tc.input), &kp); err != nil {
+			t.Errorf("[%d] unexpected error: %v", i, err)
+			continue
+		}
+		var names []string
+		for _, c := range kp.Spec.InitContainers {
+			names = append(names, c.Name)
+		}
+		if !reflect.DeepEqual(names, tc.expect) {
+			t.Errorf("[%d] expected init containers %v, got %v", i, tc.expect, names)
+		}
+	}
+	if err := decodeKubePod([]byte(`not json`), &kv1.Pod{}); err == nil {
+		t.Errorf("expected error for invalid json")
+	}
+}
+
+func TestDecodeKubeNode(t *testing.T) {
+	testCases := []struct {
+		input  string
+		expect []kv1.Taint
+	}{
+		{
+			input:  `{"metadata": {"name": "a"}, "spec": {"providerID": "aws://a"}}`,
+			expect: nil,
+		},
+		{
+			input: `{"spec": {"taints": [{"key": "dedicated", "value": "db", "effect": "NoSchedule"}]}}`,
+			expect: []kv1.Taint{
+				{Key: "dedicated", Value: "db", Effect: kv1.TaintEffectNoSchedule},
+			},
+		},
+		{ // the spec wins over the annotation
+			input: `{"metadata": {"annotations": {"scheduler.alpha.kubernetes.io/taints": "[{\"key\": \"old\", \"effect\": \"NoSchedule\"}]"}},
+				"spec": {"taints": [{"key": "new", "effect": "NoExecute"}]}}`,
+			expect: []kv1.Taint{
+				{Key: "new", Effect: "NoExecute"},
+			},
+		},
+		{ // older versions of kubernetes only had an annotation
+			input: `{"metadata": {"annotations": {"scheduler.alpha.kubernetes.io/taints": "[{\"key\": \"old\", \"effect\": \"NoSchedule\"}]"}}}`,
+			expect: []kv1.Taint{
+				{Key: "old", Effect: kv1.TaintEffectNoSchedule},
+			},
+		},
+		{ // an annotation that can not be parsed is ignored
+			input:  `{"metadata": {"annotations": {"scheduler.alpha.kubernetes.io/taints": "garbage"}}}`,
+			expect: nil,
+		},
+	}
+
+	for i, tc := range testCases {
+		var kn kv1.Node
+		taints, err := decodeKubeNode([]byte(tc.input), &kn)
+		if err != nil {
+			t.Errorf("[%d] unexpected error: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(taints, tc.expect) {
+			t.Errorf("[%d] did not get expected taints:\n%s", i, pretty.Compare(taints, tc.expect))
+		}
+	}
+	if _, err := decodeKubeNode([]byte(`not json`), &kv1.Node{}); err == nil {
+		t.Errorf("expected error for invalid json")
+	}
+}
+
+func TestServicesFromKubeJSON(t *testing.T) {
+	items := []json.RawMessage{
+		json.RawMessage(`{"spec": {"type": "ClusterIP", "clusterIP": "10.0.0.1"}}`),
+		json.RawMessage(`{"spec": {"type": "ClusterIP", "clusterIP": "10.0.0.2", "clusterIPs": ["10.0.0.2", "fd00::2"]}}`),
+		json.RawMessage(`{"spec": {"type": "ExternalName"}}`),
+	}
+	expect := []service{
+		{serviceType: "ClusterIP", clusterIPs: []string{"10.0.0.1"}},
+		{serviceType: "ClusterIP", clusterIPs: []string{"10.0.0.2", "fd00::2"}},
+		{serviceType: "ExternalName"},
+	}
+
+	services, err := servicesFromKubeJSON(items)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(services, expect) {
+		t.Errorf("expected %+v, got %+v", expect, services)
+	}
+	if _, err := servicesFromKubeJSON([]json.RawMessage{json.RawMessage(`[]`)}); err == nil {
+		t.Errorf("expected error for invalid service")
+	}
+}
+
+func TestPodCIDRsFromKubeJSON(t *testing.T) {
+	items := []json.RawMessage{
+		json.RawMessage(`{"spec": {"podCIDR": "10.244.0.0/24"}}`),
+		json.RawMessage(`{"spec": {"podCIDR": "10.244.1.0/24", "podCIDRs": ["10.244.1.0/24", "fd00:10:244:1::/64"]}}`),
+		json.RawMessage(`{"spec": {}}`),
+	}
+	expect := []string{"10.244.0.0/24", "10.244.1.0/24", "fd00:10:244:1::/64"}
+
+	cidrs, err := podCIDRsFromKubeJSON(items)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(cidrs, expect) {
+		t.Errorf("expected %v, got %v", expect, cidrs)
+	}
+}
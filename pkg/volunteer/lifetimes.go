@@ -0,0 +1,146 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+)
+
+// objectTimes is what we need to know about an object to measure its
+// lifetime.
+type objectTimes struct {
+	uid     string
+	created time.Time
+}
+
+type objectLister interface {
+	// ListObjects returns the objects of each kind whose lifetimes are
+	// measured.
+	ListObjects() (map[string][]objectTimes, error)
+}
+
+// reportedPercentiles are the percentiles reported for each distribution.
+var reportedPercentiles = []int64{10, 50, 90, 99}
+
+// lifetimeTracker measures the lifetimes of objects.  The ages of live
+// objects come straight from their creation timestamps, but deleted objects
+// are gone from the API, so the tracker remembers what it saw at the previous
+// report.  That state is only kept in memory: it is lost when the volunteer
+// restarts, and UIDs are never reported.
+type lifetimeTracker struct {
+	lister objectLister
+	now    func() time.Time
+	// previous is the creation time of each object seen at the previous
+	// report, by kind and UID.
+	previous map[string]map[string]time.Time
+	// previousTime is when the previous report was generated.
+	previousTime time.Time
+}
+
+func newLifetimeTracker(lister objectLister) *lifetimeTracker {
+	return &lifetimeTracker{
+		lister: lister,
+		now:    time.Now,
+	}
+}
+
+// Lifetimes returns the lifetime statistics for each kind of object, and
+// remembers the objects it saw for the next call.
+func (lt *lifetimeTracker) Lifetimes() ([]report.Lifetime, error) {
+	objects, err := lt.lister.ListObjects()
+	if err != nil {
+		return nil, err
+	}
+	now := lt.now()
+
+	// We want to iterate the kinds in a deterministic order.
+	kinds := []string{}
+	for k := range objects {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+
+	lifetimes := []report.Lifetime{}
+	current := map[string]map[string]time.Time{}
+	for _, kind := range kinds {
+		seen := map[string]time.Time{}
+		ages := []time.Duration{}
+		for _, o := range objects[kind] {
+			seen[o.uid] = o.created
+			ages = append(ages, now.Sub(o.created))
+		}
+		l := report.Lifetime{
+			Kind: kind,
+			Live: distributionOf(ages),
+		}
+		if prev, found := lt.previous[kind]; found {
+			deleted := []time.Duration{}
+			for uid, created := range prev {
+				if _, found := seen[uid]; !found {
+					deleted = append(deleted, lt.previousTime.Sub(created))
+				}
+			}
+			l.Deleted = distributionOf(deleted)
+		}
+		lifetimes = append(lifetimes, l)
+		current[kind] = seen
+	}
+
+	lt.previous = current
+	lt.previousTime = now
+	return lifetimes, nil
+}
+
+// distributionOf summarizes a list of durations.  Percentiles use the
+// nearest-rank method, so every reported value is one that was measured.
+func distributionOf(durations []time.Duration) *report.Distribution {
+	secs := make([]int64, len(durations))
+	var total int64
+	for i, d := range durations {
+		// Creation timestamps come from the master's clock, which may be
+		// ahead of ours.
+		if d < 0 {
+			d = 0
+		}
+		secs[i] = int64(d / time.Second)
+		total += secs[i]
+	}
+	sort.Sort(int64Slice(secs))
+
+	dist := &report.Distribution{Count: int64(len(secs))}
+	if len(secs) == 0 {
+		return dist
+	}
+	dist.AverageSeconds = total / int64(len(secs))
+	for _, p := range reportedPercentiles {
+		rank := (p*int64(len(secs)) + 99) / 100
+		dist.Percentiles = append(dist.Percentiles, report.Percentile{
+			Percentile: p,
+			Seconds:    secs[rank-1],
+		})
+	}
+	return dist
+}
+
+type int64Slice []int64
+
+func (s int64Slice) Len() int           { return len(s) }
+func (s int64Slice) Less(i, j int) bool { return s[i] < s[j] }
+func (s int64Slice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
@@ -0,0 +1,128 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestDistributionOf(t *testing.T) {
+	testCases := []struct {
+		input  []time.Duration
+		expect *report.Distribution
+	}{
+		{
+			input:  nil,
+			expect: &report.Distribution{},
+		},
+		{
+			input: []time.Duration{-time.Second, 90 * time.Second},
+			expect: &report.Distribution{
+				Count:          2,
+				AverageSeconds: 45,
+				Percentiles: []report.Percentile{
+					{Percentile: 10, Seconds: 0},
+					{Percentile: 50, Seconds: 0},
+					{Percentile: 90, Seconds: 90},
+					{Percentile: 99, Seconds: 90},
+				},
+			},
+		},
+		{
+			input: []time.Duration{
+				10 * time.Second, 9 * time.Second, 8 * time.Second, 7 * time.Second, 6 * time.Second,
+				5 * time.Second, 4 * time.Second, 3 * time.Second, 2 * time.Second, 1 * time.Second,
+			},
+			expect: &report.Distribution{
+				Count:          10,
+				AverageSeconds: 5,
+				Percentiles: []report.Percentile{
+					{Percentile: 10, Seconds: 1},
+					{Percentile: 50, Seconds: 5},
+					{Percentile: 90, Seconds: 9},
+					{Percentile: 99, Seconds: 10},
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		dist := distributionOf(tc.input)
+		if !reflect.DeepEqual(dist, tc.expect) {
+			t.Errorf("[%d] did not get expected result:\n%s", i, pretty.Compare(dist, tc.expect))
+		}
+	}
+}
+
+func TestLifetimeTracker(t *testing.T) {
+	start := time.Unix(1478020000, 0)
+	now := start
+	lister := &fakeObjectLister{}
+	lt := newLifetimeTracker(lister)
+	lt.now = func() time.Time { return now }
+
+	// The first report can not know about deleted objects.
+	lister.returnValue = map[string][]objectTimes{
+		"pods": {
+			{uid: "a", created: start.Add(-time.Hour)},
+			{uid: "b", created: start.Add(-time.Minute)},
+		},
+	}
+	lifetimes, err := lt.Lifetimes()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(lifetimes) != 1 || lifetimes[0].Live.Count != 2 || lifetimes[0].Deleted != nil {
+		t.Errorf("unexpected first report:\n%s", pretty.Sprint(lifetimes))
+	}
+
+	// A failure must not lose what was seen before.
+	lister.returnError = fmt.Errorf("fail")
+	if _, err := lt.Lifetimes(); err == nil {
+		t.Errorf("expected error: no error")
+	}
+	lister.returnError = nil
+
+	// Pod "a" is deleted, and its lifetime is measured up to the previous
+	// report.
+	now = start.Add(24 * time.Hour)
+	lister.returnValue = map[string][]objectTimes{
+		"pods": {
+			{uid: "b", created: start.Add(-time.Minute)},
+		},
+	}
+	lifetimes, err = lt.Lifetimes()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expect := []report.Lifetime{
+		{
+			Kind:    "pods",
+			Live:    distributionOf([]time.Duration{24*time.Hour + time.Minute}),
+			Deleted: distributionOf([]time.Duration{time.Hour}),
+		},
+	}
+	if !reflect.DeepEqual(lifetimes, expect) {
+		t.Errorf("did not get expected result:\n%s", pretty.Compare(lifetimes, expect))
+	}
+}
@@ -0,0 +1,328 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+)
+
+// metricsAcceptHeader asks for the OpenMetrics text format, or else the
+// Prometheus text format, which is parsed in the same way.
+const metricsAcceptHeader = "application/openmetrics-text; version=1.0.0,text/plain;version=0.0.4;q=0.5"
+
+// maxMetricsBytes limits how much of a metrics endpoint's response is read,
+// and so also how long a line of it can be.
+const maxMetricsBytes = 8 << 20
+
+// serviceAccountTokenFile and serviceAccountCAFile are where the token and
+// the cluster's CA bundle of the pod's service account are mounted.
+const (
+	serviceAccountTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	serviceAccountCAFile    = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+)
+
+// metricsExtensionsLister is an implementation of the extensionsLister
+// interface that scrapes endpoints in the OpenMetrics or Prometheus text
+// format, and reports the gauges and counters whose names are allowed.
+// Each sample is a float extension, named by the metric and its labels,
+// e.g. `scheduler_pending_pods.queue.active`.  The endpoints are scraped
+// again for every report.
+type metricsExtensionsLister struct {
+	client *http.Client
+	// endpoints are the URLs to scrape.
+	endpoints []string
+	// allowlist is the names of the metrics to report.  Any metric that is
+	// not in it is ignored.
+	allowlist []string
+	// tokenFile is a file with a bearer token to send to https endpoints,
+	// or empty to send none.  It is read for every scrape, because service
+	// account tokens are rotated.
+	tokenFile string
+}
+
+// newMetricsClient returns an HTTP client for scraping metrics endpoints
+// that also trusts the CAs in a file, such as the cluster's CA bundle.
+func newMetricsClient(timeout time.Duration, caFile string) (*http.Client, error) {
+	ca, err := ioutil.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %v", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{RootCAs: pool},
+		},
+	}, nil
+}
+
+// ListExtensions returns a slice of report.Extensions containing the
+// selected metrics.  Each endpoint is a source of its own, in the order they
+// were configured.
+func (m metricsExtensionsLister) ListExtensions() ([]extensionsSource, error) {
+	merger := newExtensionsMerger()
+	for _, endpoint := range m.endpoints {
+		merger.add(m.scrape(endpoint))
+	}
+	return merger.sources, nil
+}
+
+// scrape fetches one endpoint and converts the selected metrics to
+// extensions.  An endpoint that can not be scraped contributes no
+// extensions, but does not stop the others from being reported.
+func (m metricsExtensionsLister) scrape(endpoint string) (report.ExtensionDiagnostic, []report.Extension) {
+	diag := report.ExtensionDiagnostic{Source: "metrics " + endpoint}
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		diag.Errors = append(diag.Errors, fmt.Sprintf("invalid endpoint: %v", err))
+		return diag, nil
+	}
+	req.Header.Set("Accept", metricsAcceptHeader)
+	// The token is never sent in the clear.
+	if m.tokenFile != "" && req.URL.Scheme == "https" {
+		token, err := ioutil.ReadFile(m.tokenFile)
+		if err != nil {
+			diag.Errors = append(diag.Errors, fmt.Sprintf("failed to read token: %v", err))
+			return diag, nil
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+	resp, err := m.client.Do(req)
+	if err != nil {
+		diag.Errors = append(diag.Errors, fmt.Sprintf("failed to scrape: %v", err))
+		return diag, nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		diag.Errors = append(diag.Errors, fmt.Sprintf("failed to scrape: unexpected status %s", resp.Status))
+		return diag, nil
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxMetricsBytes))
+	if err != nil {
+		diag.Errors = append(diag.Errors, fmt.Sprintf("failed to scrape: %v", err))
+		return diag, nil
+	}
+
+	all, errs := extensionsFromMetrics(b, m.allowlist)
+	extensions, diag := checkExtensions(diag.Source, all)
+	diag.Errors = append(errs, diag.Errors...)
+	return diag, extensions
+}
+
+// extensionsFromMetrics parses metrics in the OpenMetrics or Prometheus text
+// format, and converts the samples of the allowed gauges and counters to
+// extensions.  Samples that can not be parsed, or that are not finite, are
+// returned as errors instead.
+func extensionsFromMetrics(b []byte, allowlist []string) ([]report.Extension, []string) {
+	var extensions []report.Extension
+	var errs []string
+
+	// The type of each metric family, by name.
+	types := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	// Lines with many labels can be longer than the default limit.
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMetricsBytes)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			fields := strings.Fields(line)
+			if len(fields) >= 4 && fields[1] == "TYPE" {
+				types[fields[2]] = fields[3]
+			}
+			continue
+		}
+
+		name, rest := splitMetricName(line)
+		family, ok := metricFamily(types, name)
+		if !ok || !(isOneOf(family, allowlist) || isOneOf(name, allowlist)) {
+			continue
+		}
+		labels, rest, err := parseMetricLabels(rest)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("line %d: %v", n, err))
+			continue
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			errs = append(errs, fmt.Sprintf("line %d: missing value", n))
+			continue
+		}
+		f, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("line %d: invalid value %q", n, fields[0]))
+			continue
+		}
+		extName := metricExtensionName(name, labels)
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			errs = append(errs, fmt.Sprintf("%q: value %s is not finite", extName, fields[0]))
+			continue
+		}
+		extensions = append(extensions, report.Extension{
+			Name:       extName,
+			Value:      strconv.FormatFloat(f, 'f', -1, 64),
+			Type:       report.ExtensionTypeFloat,
+			FloatValue: &f,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, fmt.Sprintf("failed to read metrics: %v", err))
+	}
+	return extensions, errs
+}
+
+// metricFamily returns the name of the family that a sample belongs to, if
+// it is a gauge or a counter.  In the OpenMetrics format the samples of a
+// counter have a "_total" suffix that its family does not.
+func metricFamily(types map[string]string, name string) (string, bool) {
+	switch types[name] {
+	case "gauge", "counter":
+		return name, true
+	}
+	if family := strings.TrimSuffix(name, "_total"); family != name && types[family] == "counter" {
+		return family, true
+	}
+	return "", false
+}
+
+// splitMetricName splits a sample line after its metric name.
+func splitMetricName(line string) (string, string) {
+	i := strings.IndexAny(line, "{ \t")
+	if i < 0 {
+		return line, ""
+	}
+	return line[:i], line[i:]
+}
+
+// parseMetricLabels parses the label set at the start of s, if there is one,
+// and returns what follows it.
+func parseMetricLabels(s string) (map[string]string, string, error) {
+	if !strings.HasPrefix(s, "{") {
+		return nil, s, nil
+	}
+	labels := map[string]string{}
+	s = s[1:]
+	for {
+		s = strings.TrimLeft(s, " ,")
+		if strings.HasPrefix(s, "}") {
+			return labels, s[1:], nil
+		}
+		eq := strings.Index(s, "=")
+		if eq < 0 {
+			return nil, "", fmt.Errorf("invalid label set")
+		}
+		key := strings.TrimSpace(s[:eq])
+		s = strings.TrimSpace(s[eq+1:])
+		value, rest, err := parseLabelValue(s)
+		if err != nil {
+			return nil, "", fmt.Errorf("label %q: %v", key, err)
+		}
+		labels[key] = value
+		s = rest
+	}
+}
+
+// parseLabelValue parses the quoted label value at the start of s, and
+// returns what follows it.
+func parseLabelValue(s string) (string, string, error) {
+	if !strings.HasPrefix(s, `"`) {
+		return "", "", fmt.Errorf("value must be quoted")
+	}
+	var value []byte
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"':
+			return string(value), s[i+1:], nil
+		case '\\':
+			if i+1 == len(s) {
+				return "", "", fmt.Errorf("unterminated value")
+			}
+			i++
+			if s[i] == 'n' {
+				value = append(value, '\n')
+			} else {
+				value = append(value, s[i])
+			}
+		default:
+			value = append(value, c)
+		}
+	}
+	return "", "", fmt.Errorf("unterminated value")
+}
+
+// labelValueEscaper percent-encodes the characters of label values that
+// have a meaning in extension names: '/' separates the prefix, and '.'
+// separates the labels.
+var labelValueEscaper = strings.NewReplacer("%", "%25", "/", "%2F", ".", "%2E")
+
+// metricExtensionName flattens a metric's labels into the extension name,
+// in the order of the label names, as if they were nested objects, e.g.
+// `scheduler_pending_pods.queue.active`.  Label values are escaped, so that
+// e.g. path="/api/v1" is reported as `path.%2Fapi%2Fv1`.
+func metricExtensionName(name string, labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := []string{name}
+	for _, k := range keys {
+		parts = append(parts, k, labelValueEscaper.Replace(labels[k]))
+	}
+	return strings.Join(parts, ".")
+}
+
+// validMetricsEndpoint checks that a metrics endpoint is an HTTP or HTTPS
+// URL.
+func validMetricsEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("must be an http or https URL")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("must have a host")
+	}
+	return nil
+}
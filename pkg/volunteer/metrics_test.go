@@ -0,0 +1,326 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func floatExtension(name string, value float64, str string) report.Extension {
+	return report.Extension{Name: name, Value: str, Type: report.ExtensionTypeFloat, FloatValue: float64Ptr(value)}
+}
+
+func TestExtensionsFromMetrics(t *testing.T) {
+	testCases := []struct {
+		metrics   string
+		allowlist []string
+		expect    []report.Extension
+		errstrs   []string
+	}{
+		{ // Nothing allowed.
+			metrics: "# TYPE etcd_db_size gauge\netcd_db_size 42\n",
+			expect:  nil,
+		},
+		{ // A gauge without labels, in the Prometheus format.
+			metrics:   "# HELP etcd_db_size Size of the DB.\n# TYPE etcd_db_size gauge\netcd_db_size 2.68435456e+08\n",
+			allowlist: []string{"etcd_db_size"},
+			expect:    []report.Extension{floatExtension("etcd_db_size", 268435456, "268435456")},
+		},
+		{ // Labels are flattened in order, and timestamps are ignored.
+			metrics: strings.Join([]string{
+				`# TYPE scheduler_pending_pods gauge`,
+				`scheduler_pending_pods{queue="active"} 3 1500000000000`,
+				`scheduler_pending_pods{queue="backoff",zone="b"} 1.5`,
+				`scheduler_pending_pods{zone="a",queue="unschedulable",} 0`,
+			}, "\n"),
+			allowlist: []string{"scheduler_pending_pods"},
+			expect: []report.Extension{
+				floatExtension("scheduler_pending_pods.queue.active", 3, "3"),
+				floatExtension("scheduler_pending_pods.queue.backoff.zone.b", 1.5, "1.5"),
+				floatExtension("scheduler_pending_pods.queue.unschedulable.zone.a", 0, "0"),
+			},
+		},
+		{ // An OpenMetrics counter, selected by its family or sample name.
+			metrics: strings.Join([]string{
+				`# TYPE requests counter`,
+				`requests_total{code="200"} 10 # {trace_id="abc"} 1.0`,
+				`requests_created{code="200"} 1.5e+09`,
+				`# TYPE errors counter`,
+				`errors_total 2`,
+				`# EOF`,
+			}, "\n"),
+			allowlist: []string{"requests", "errors_total"},
+			expect: []report.Extension{
+				floatExtension("requests_total.code.200", 10, "10"),
+				floatExtension("errors_total", 2, "2"),
+			},
+		},
+		{ // Histograms, summaries and untyped metrics are ignored.
+			metrics: strings.Join([]string{
+				`# TYPE latency histogram`,
+				`latency_bucket{le="1"} 1`,
+				`latency_sum 1`,
+				`# TYPE quantiles summary`,
+				`quantiles{quantile="0.5"} 1`,
+				`untyped 1`,
+			}, "\n"),
+			allowlist: []string{"latency", "latency_bucket", "latency_sum", "quantiles", "untyped"},
+			expect:    nil,
+		},
+		{ // Escaped label values.
+			metrics:   "# TYPE info gauge\ninfo{name=\"a\\\"b\\\\c\"} 1\n",
+			allowlist: []string{"info"},
+			expect:    []report.Extension{floatExtension(`info.name.a"b\c`, 1, "1")},
+		},
+		{ // Label values that have a meaning in extension names.
+			metrics: strings.Join([]string{
+				`# TYPE requests counter`,
+				`requests_total{path="/api/v1",version="1.2"} 7`,
+				`requests_total{url="https://example.com/a%20b"} 8`,
+			}, "\n"),
+			allowlist: []string{"requests"},
+			expect: []report.Extension{
+				floatExtension("requests_total.path.%2Fapi%2Fv1.version.1%2E2", 7, "7"),
+				floatExtension("requests_total.url.https:%2F%2Fexample%2Ecom%2Fa%2520b", 8, "8"),
+			},
+		},
+		{ // Lines longer than the default limit of bufio.Scanner.
+			metrics:   "# TYPE long gauge\nlong{a=\"" + strings.Repeat("x", 100*1024) + "\"} 1\n# TYPE short gauge\nshort 2\n",
+			allowlist: []string{"short"},
+			expect:    []report.Extension{floatExtension("short", 2, "2")},
+		},
+		{ // Bad samples are errors, and do not stop the rest.
+			metrics: strings.Join([]string{
+				`# TYPE g gauge`,
+				`g{a="1"} NaN`,
+				`g{a="2"} +Inf`,
+				`g{a="3"} bogus`,
+				`g{a=4} 1`,
+				`g{a="5"}`,
+				`g{a="6"} 6`,
+			}, "\n"),
+			allowlist: []string{"g"},
+			expect:    []report.Extension{floatExtension("g.a.6", 6, "6")},
+			errstrs: []string{
+				`"g.a.1": value NaN is not finite`,
+				`"g.a.2": value +Inf is not finite`,
+				`line 4: invalid value "bogus"`,
+				`line 5: label "a": value must be quoted`,
+				`line 6: missing value`,
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		got, errs := extensionsFromMetrics([]byte(tc.metrics), tc.allowlist)
+		if !reflect.DeepEqual(got, tc.expect) {
+			t.Errorf("[%d] did not get expected result:\n%s", i, pretty.Compare(got, tc.expect))
+		}
+		if !reflect.DeepEqual(errs, tc.errstrs) {
+			t.Errorf("[%d] expected errors %q, got %q", i, tc.errstrs, errs)
+		}
+	}
+}
+
+func TestMetricsExtensionsLister(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/etcd":
+			fmt.Fprint(w, "# TYPE etcd_db_size gauge\netcd_db_size 1024\n# TYPE other gauge\nother 1\n")
+		case "/scheduler":
+			fmt.Fprint(w, "# TYPE etcd_db_size gauge\netcd_db_size 2048\n# TYPE pending gauge\npending{path=\"/a/b\"} 1\n")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	lister := metricsExtensionsLister{
+		client:    http.DefaultClient,
+		endpoints: []string{server.URL + "/etcd", server.URL + "/scheduler", server.URL + "/missing"},
+		allowlist: []string{"etcd_db_size", "pending"},
+	}
+	sources, err := lister.ListExtensions()
+	extensions, diagnostics := flattenExtensions(sources)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	expect := []report.Extension{
+		floatExtension("etcd_db_size", 1024, "1024"),
+		floatExtension("pending.path.%2Fa%2Fb", 1, "1"),
+	}
+	if !reflect.DeepEqual(extensions, expect) {
+		t.Errorf("did not get expected extensions:\n%s", pretty.Compare(extensions, expect))
+	}
+
+	expectDiags := []struct {
+		source string
+		count  int64
+		errstr string
+	}{
+		{"metrics " + server.URL + "/etcd", 1, ""},
+		{"metrics " + server.URL + "/scheduler", 1, "duplicate of a key"},
+		{"metrics " + server.URL + "/missing", 0, "404"},
+	}
+	if len(diagnostics) != len(expectDiags) {
+		t.Fatalf("expected %d diagnostics, got %v", len(expectDiags), diagnostics)
+	}
+	for i, tc := range expectDiags {
+		d := diagnostics[i]
+		if d.Source != tc.source || d.Count != tc.count {
+			t.Errorf("[%d] expected source %q with %d extensions, got %q with %d", i, tc.source, tc.count, d.Source, d.Count)
+		}
+		if tc.errstr == "" && len(d.Errors) != 0 {
+			t.Errorf("[%d] unexpected errors %v", i, d.Errors)
+		} else if tc.errstr != "" && (len(d.Errors) == 0 || !strings.Contains(d.Errors[0], tc.errstr)) {
+			t.Errorf("[%d] expected error %q, got %v", i, tc.errstr, d.Errors)
+		}
+	}
+	// The first endpoint wins a duplicate.
+	if d := diagnostics[1]; len(d.Errors) != 1 {
+		t.Errorf("expected only a duplicate key error, got %v", d.Errors)
+	}
+}
+
+func TestValidMetricsEndpoint(t *testing.T) {
+	testCases := []struct {
+		endpoint string
+		errstr   string
+	}{
+		{"http://etcd.kube-system:2379/metrics", ""},
+		{"https://10.0.0.1/metrics", ""},
+		{"etcd:2379/metrics", "must be an http or https URL"},
+		{"file:///metrics", "must be an http or https URL"},
+		{"http:///metrics", "must have a host"},
+		{"http://%zz", "invalid URL escape"},
+	}
+	for i, tc := range testCases {
+		err := validMetricsEndpoint(tc.endpoint)
+		if err != nil && tc.errstr == "" {
+			t.Errorf("[%d] unexpected error: %v", i, err)
+		} else if err != nil {
+			if !strings.Contains(err.Error(), tc.errstr) {
+				t.Errorf("[%d] expected error containing %q, got %q", i, tc.errstr, err)
+			}
+		} else if err == nil && tc.errstr != "" {
+			t.Errorf("[%d] expected error containing %q", i, tc.errstr)
+		}
+	}
+}
+
+func TestMetricsExtensionsListerAuth(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, "# TYPE apiserver_storage_objects gauge\napiserver_storage_objects{resource=\"pods\"} 12\n")
+	})
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+	plain := httptest.NewServer(handler)
+	defer plain.Close()
+
+	dir, err := ioutil.TempDir("", "metrics")
+	if err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	caFile := filepath.Join(dir, "ca.crt")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.TLS.Certificates[0].Certificate[0]})
+	if err := ioutil.WriteFile(caFile, ca, 0644); err != nil {
+		t.Fatalf("failed to write CA: %v", err)
+	}
+	tokenFile := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(tokenFile, []byte("s3cret\n"), 0600); err != nil {
+		t.Fatalf("failed to write token: %v", err)
+	}
+
+	client, err := newMetricsClient(time.Second, caFile)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	lister := metricsExtensionsLister{
+		client:    client,
+		endpoints: []string{server.URL, plain.URL},
+		allowlist: []string{"apiserver_storage_objects"},
+		tokenFile: tokenFile,
+	}
+	sources, err := lister.ListExtensions()
+	extensions, diagnostics := flattenExtensions(sources)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	expect := []report.Extension{floatExtension("apiserver_storage_objects.resource.pods", 12, "12")}
+	if !reflect.DeepEqual(extensions, expect) {
+		t.Errorf("did not get expected extensions:\n%s", pretty.Compare(extensions, expect))
+	}
+	// The token is not sent to the plain http endpoint.
+	if d := diagnostics[1]; len(d.Errors) != 1 || !strings.Contains(d.Errors[0], "401") {
+		t.Errorf("expected the plain endpoint to be unauthorized, got %v", d)
+	}
+
+	lister.tokenFile = filepath.Join(dir, "missing")
+	if sources, _ := lister.ListExtensions(); len(sources[0].diagnostic.Errors) != 1 || !strings.Contains(sources[0].diagnostic.Errors[0], "failed to read token") {
+		t.Errorf("expected a token error, got %v", sources[0].diagnostic)
+	}
+}
+
+func TestNewMetricsClient(t *testing.T) {
+	dir, err := ioutil.TempDir("", "metrics")
+	if err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	bogus := filepath.Join(dir, "bogus.crt")
+	if err := ioutil.WriteFile(bogus, []byte("not a certificate"), 0644); err != nil {
+		t.Fatalf("failed to write CA: %v", err)
+	}
+
+	testCases := []struct {
+		caFile string
+		errstr string
+	}{
+		{filepath.Join(dir, "missing.crt"), "failed to read CA bundle"},
+		{bogus, "no certificates found"},
+	}
+	for i, tc := range testCases {
+		_, err := newMetricsClient(time.Second, tc.caFile)
+		if err != nil && tc.errstr == "" {
+			t.Errorf("[%d] unexpected error: %v", i, err)
+		} else if err != nil {
+			if !strings.Contains(err.Error(), tc.errstr) {
+				t.Errorf("[%d] expected error containing %q, got %q", i, tc.errstr, err)
+			}
+		} else if err == nil && tc.errstr != "" {
+			t.Errorf("[%d] expected error containing %q", i, tc.errstr)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"sort"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+)
+
+// namespaceCounts is the number of objects of each kind in a single
+// namespace.  The namespace's name is deliberately not kept - that is PII.
+type namespaceCounts map[string]int64
+
+type namespaceLister interface {
+	ListNamespaces() ([]namespaceCounts, error)
+}
+
+// bucketBounds are the inclusive upper bounds of the histogram buckets.  The
+// last bucket is unbounded.  Buckets are coarse on purpose, so that a single
+// namespace can not be singled out by its exact object count.
+var bucketBounds = []int64{0, 1, 5, 10, 20, 50, 100, 200, 500, 1000}
+
+// namespacesFromCounts builds the report section for a list of namespaces.
+// There is one histogram per kind, and only non-empty buckets are reported.
+func namespacesFromCounts(counts []namespaceCounts) *report.Namespaces {
+	ns := &report.Namespaces{Count: int64(len(counts))}
+
+	// We want to iterate the kinds in a deterministic order.
+	kinds := map[string]bool{}
+	for _, c := range counts {
+		for k := range c {
+			kinds[k] = true
+		}
+	}
+	keys := []string{}
+	for k := range kinds {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		values := make([]int64, len(counts))
+		for i, c := range counts {
+			values[i] = c[k]
+		}
+		ns.Histograms = append(ns.Histograms, makeHistogram(k, values))
+	}
+	return ns
+}
+
+func makeHistogram(kind string, values []int64) report.Histogram {
+	return report.Histogram{Kind: kind, Buckets: makeBuckets(bucketBounds, values)}
+}
+
+// makeBuckets counts the values in the buckets with the given inclusive upper
+// bounds, plus an unbounded last bucket.  Only non-empty buckets are
+// returned.
+func makeBuckets(bounds []int64, values []int64) []report.Bucket {
+	// One extra bucket for values above the last bound.
+	totals := make([]int64, len(bounds)+1)
+	for _, v := range values {
+		totals[sort.Search(len(bounds), func(i int) bool { return v <= bounds[i] })]++
+	}
+
+	var buckets []report.Bucket
+	min := int64(0)
+	for i, n := range totals {
+		var max *int64
+		if i < len(bounds) {
+			max = new(int64)
+			*max = bounds[i]
+		}
+		if n > 0 {
+			buckets = append(buckets, report.Bucket{Min: min, Max: max, Count: n})
+		}
+		if max != nil {
+			min = *max + 1
+		}
+	}
+	return buckets
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
+
+func TestNamespacesFromCounts(t *testing.T) {
+	testCases := []struct {
+		input  []namespaceCounts
+		expect *report.Namespaces
+	}{
+		{
+			input:  nil,
+			expect: &report.Namespaces{},
+		},
+		{
+			input: []namespaceCounts{
+				{"pods": 0, "services": 1},
+				{"pods": 0, "services": 2},
+				{"pods": 7, "services": 5},
+				{"pods": 5000},
+			},
+			expect: &report.Namespaces{
+				Count: 4,
+				Histograms: []report.Histogram{
+					{
+						Kind: "pods",
+						Buckets: []report.Bucket{
+							{Min: 0, Max: int64Ptr(0), Count: 2},
+							{Min: 6, Max: int64Ptr(10), Count: 1},
+							{Min: 1001, Count: 1},
+						},
+					},
+					{
+						Kind: "services",
+						Buckets: []report.Bucket{
+							{Min: 0, Max: int64Ptr(0), Count: 1},
+							{Min: 1, Max: int64Ptr(1), Count: 1},
+							{Min: 2, Max: int64Ptr(5), Count: 2},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		ns := namespacesFromCounts(tc.input)
+		if !reflect.DeepEqual(ns, tc.expect) {
+			t.Errorf("[%d] did not get expected result:\n%s", i, pretty.Compare(ns, tc.expect))
+		}
+	}
+}
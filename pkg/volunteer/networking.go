@@ -0,0 +1,241 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+)
+
+// service is what we report about a Service.
+type service struct {
+	serviceType string
+	// clusterIPs are the cluster IPs of the service, one per IP family in
+	// dual-stack clusters.
+	clusterIPs []string
+}
+
+// daemonSet is what we report about a DaemonSet in kube-system: its name is
+// matched against well-known names, and the arguments of its containers are
+// searched for flags.
+type daemonSet struct {
+	name string
+	args []string
+	// configs are the data of the ConfigMaps that its pods mount, which
+	// kube-proxy can read its configuration from.  They are only read for
+	// kube-proxy.
+	configs []string
+}
+
+type networkObjects struct {
+	services []service
+	// podCIDRs are the pod CIDRs of the nodes, one per IP family in
+	// dual-stack clusters.
+	podCIDRs   []string
+	daemonSets []daemonSet
+}
+
+type networkLister interface {
+	ListNetwork() (networkObjects, error)
+}
+
+const (
+	// headlessServiceType is reported for ClusterIP services without a
+	// cluster IP.
+	headlessServiceType = "Headless"
+	// clusterIPNone is the cluster IP of headless services.
+	clusterIPNone = "None"
+)
+
+const (
+	// kubeProxyName is the name of the kube-proxy DaemonSet.
+	kubeProxyName = "kube-proxy"
+	// proxyModeFlag is kube-proxy's flag for its mode.
+	proxyModeFlag = "--proxy-mode"
+	// proxyConfigFlag is kube-proxy's flag for its config file.
+	proxyConfigFlag = "--config"
+	// proxyConfigKind is the kind of kube-proxy's config file.
+	proxyConfigKind = "KubeProxyConfiguration"
+	// defaultProxyMode is reported if kube-proxy does not set its mode.
+	defaultProxyMode = "default"
+	// otherProxyMode is reported for modes that are not well-known.
+	otherProxyMode = "other"
+	// unknownProxyMode is reported if kube-proxy's mode is in a config file
+	// that can not be read.
+	unknownProxyMode = "unknown"
+)
+
+// knownProxyModes are the kube-proxy modes that are reported verbatim.
+var knownProxyModes = map[string]bool{
+	"iptables":    true,
+	"ipvs":        true,
+	"kernelspace": true,
+	"nftables":    true,
+	"userspace":   true,
+}
+
+// cniPlugins are the well-known CNI plugins and the names of their DaemonSets
+// in kube-system.  A name also matches with a suffix, as in ecosystemCatalog.
+var cniPlugins = []struct {
+	id         string
+	daemonSets []string
+}{
+	{id: "antrea", daemonSets: []string{"antrea-agent"}},
+	{id: "aws-vpc-cni", daemonSets: []string{"aws-node"}},
+	{id: "azure-cni", daemonSets: []string{"azure-cni-networkmonitor", "azure-ip-masq-agent"}},
+	{id: "calico", daemonSets: []string{"calico-node"}},
+	{id: "canal", daemonSets: []string{"canal"}},
+	{id: "cilium", daemonSets: []string{"cilium"}},
+	{id: "flannel", daemonSets: []string{"kube-flannel"}},
+	{id: "kube-router", daemonSets: []string{"kube-router"}},
+	{id: "weave-net", daemonSets: []string{"weave-net"}},
+}
+
+// networkingFromObjects builds the report section for the network objects.
+func networkingFromObjects(objs networkObjects) *report.Networking {
+	nw := &report.Networking{ServiceCount: int64(len(objs.services))}
+
+	types := map[string]int64{}
+	addrs := []string{}
+	for _, s := range objs.services {
+		t := s.serviceType
+		if t == "" {
+			t = "ClusterIP"
+		}
+		if t == "ClusterIP" && len(s.clusterIPs) > 0 && s.clusterIPs[0] == clusterIPNone {
+			t = headlessServiceType
+		}
+		types[t]++
+		for _, ip := range s.clusterIPs {
+			if ip != "" && ip != clusterIPNone {
+				addrs = append(addrs, ip)
+			}
+		}
+	}
+	nw.ServiceTypes = valueCounts(types)
+	for _, c := range objs.podCIDRs {
+		if ip, _, err := net.ParseCIDR(c); err == nil {
+			addrs = append(addrs, ip.String())
+		}
+	}
+	nw.IPFamily = ipFamilyOf(addrs)
+
+	plugins := map[string]bool{}
+	for _, ds := range objs.daemonSets {
+		if ds.name == kubeProxyName {
+			nw.KubeProxyMode = strPtr(proxyModeOf(ds))
+		}
+		for _, p := range cniPlugins {
+			if matchesWorkload(ds.name, p.daemonSets) {
+				plugins[p.id] = true
+			}
+		}
+	}
+	for p := range plugins {
+		nw.CNIPlugins = append(nw.CNIPlugins, p)
+	}
+	// We want to report the plugins in a deterministic order.
+	sort.Strings(nw.CNIPlugins)
+
+	return nw
+}
+
+// ipFamilyOf tells which IP families the addresses belong to.
+func ipFamilyOf(addrs []string) *string {
+	v4, v6 := false, false
+	for _, a := range addrs {
+		ip := net.ParseIP(a)
+		if ip == nil {
+			continue
+		}
+		if ip.To4() != nil {
+			v4 = true
+		} else {
+			v6 = true
+		}
+	}
+	switch {
+	case v4 && v6:
+		return strPtr("DualStack")
+	case v4:
+		return strPtr("IPv4")
+	case v6:
+		return strPtr("IPv6")
+	}
+	return nil
+}
+
+// proxyModeOf finds kube-proxy's mode.  If kube-proxy has a config file,
+// which is usually mounted from a ConfigMap, the mode is read from the file,
+// and is unknown if the file can not be found.  Otherwise it is read from
+// its arguments, which can be either "--proxy-mode=ipvs" or
+// "--proxy-mode ipvs".
+func proxyModeOf(ds daemonSet) string {
+	mode := ""
+	if _, found := flagValue(ds.args, proxyConfigFlag); found {
+		m, found := proxyModeFromConfigs(ds.configs)
+		if !found {
+			return unknownProxyMode
+		}
+		mode = m
+	} else {
+		mode, _ = flagValue(ds.args, proxyModeFlag)
+	}
+	if mode == "" {
+		return defaultProxyMode
+	}
+	if !knownProxyModes[mode] {
+		return otherProxyMode
+	}
+	return mode
+}
+
+// flagValue finds the last value of a flag in arguments, which can be either
+// "--flag=value" or "--flag value", and tells whether the flag was found.
+func flagValue(args []string, flag string) (string, bool) {
+	value, found := "", false
+	for i, a := range args {
+		if strings.HasPrefix(a, flag+"=") {
+			value, found = strings.TrimPrefix(a, flag+"="), true
+		} else if a == flag && i+1 < len(args) {
+			value, found = args[i+1], true
+		}
+	}
+	return value, found
+}
+
+// proxyModeFromConfigs finds the mode in the first of the configs that is a
+// kube-proxy config file, and tells whether one was found.
+func proxyModeFromConfigs(configs []string) (string, bool) {
+	for _, c := range configs {
+		var config struct {
+			Kind string `json:"kind"`
+			Mode string `json:"mode"`
+		}
+		if err := yaml.Unmarshal([]byte(c), &config); err != nil {
+			continue
+		}
+		if config.Kind == proxyConfigKind {
+			return config.Mode, true
+		}
+	}
+	return "", false
+}
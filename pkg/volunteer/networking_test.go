@@ -0,0 +1,131 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestNetworkingFromObjects(t *testing.T) {
+	objs := networkObjects{
+		services: []service{
+			{serviceType: "ClusterIP", clusterIPs: []string{"10.0.0.1"}},
+			{serviceType: "ClusterIP", clusterIPs: []string{"None"}},
+			{serviceType: "", clusterIPs: []string{"10.0.0.2"}},
+			{serviceType: "NodePort", clusterIPs: []string{"10.0.0.3"}},
+			{serviceType: "LoadBalancer", clusterIPs: []string{"10.0.0.4"}},
+			{serviceType: "ExternalName"},
+		},
+		podCIDRs: []string{"10.244.0.0/24", "10.244.1.0/24"},
+		daemonSets: []daemonSet{
+			{name: "kube-proxy", args: []string{"/usr/local/bin/kube-proxy", "--proxy-mode=ipvs"}},
+			{name: "calico-node"},
+			{name: "kube-flannel-ds-amd64"},
+			{name: "fluentd"},
+		},
+	}
+	expect := &report.Networking{
+		ServiceCount: 6,
+		ServiceTypes: []report.ValueCount{
+			{Value: "ClusterIP", Count: 2},
+			{Value: "ExternalName", Count: 1},
+			{Value: "Headless", Count: 1},
+			{Value: "LoadBalancer", Count: 1},
+			{Value: "NodePort", Count: 1},
+		},
+		IPFamily:      strPtr("IPv4"),
+		KubeProxyMode: strPtr("ipvs"),
+		CNIPlugins:    []string{"calico", "flannel"},
+	}
+
+	nw := networkingFromObjects(objs)
+	if !reflect.DeepEqual(nw, expect) {
+		t.Errorf("did not get expected result:\n%s", pretty.Compare(nw, expect))
+	}
+	// Dual-stack services and nodes have an address of each family.
+	dual := networkingFromObjects(networkObjects{
+		services: []service{{serviceType: "ClusterIP", clusterIPs: []string{"10.0.0.1", "fd00::1"}}},
+		podCIDRs: []string{"10.244.0.0/24", "fd00:10:244::/64"},
+	})
+	if !reflect.DeepEqual(dual.IPFamily, strPtr("DualStack")) {
+		t.Errorf("expected DualStack, got %v", dual.IPFamily)
+	}
+	if nw := networkingFromObjects(networkObjects{}); !reflect.DeepEqual(nw, &report.Networking{}) {
+		t.Errorf("expected empty networking, got %v", nw)
+	}
+}
+
+func TestIPFamilyOf(t *testing.T) {
+	testCases := []struct {
+		addrs  []string
+		expect *string
+	}{
+		{addrs: nil, expect: nil},
+		{addrs: []string{"garbage"}, expect: nil},
+		{addrs: []string{"10.0.0.1", "192.168.0.1"}, expect: strPtr("IPv4")},
+		{addrs: []string{"fd00::1"}, expect: strPtr("IPv6")},
+		{addrs: []string{"10.0.0.1", "fd00::1"}, expect: strPtr("DualStack")},
+	}
+
+	for i, tc := range testCases {
+		if f := ipFamilyOf(tc.addrs); !reflect.DeepEqual(f, tc.expect) {
+			t.Errorf("[%d] expected %v, got %v", i, tc.expect, f)
+		}
+	}
+}
+
+func TestProxyModeOf(t *testing.T) {
+	ipvsConfig := "apiVersion: kubeproxy.config.k8s.io/v1alpha1\nkind: KubeProxyConfiguration\nmode: ipvs\n"
+	defaultConfig := "apiVersion: kubeproxy.config.k8s.io/v1alpha1\nkind: KubeProxyConfiguration\nmode: \"\"\n"
+	testCases := []struct {
+		args    []string
+		configs []string
+		expect  string
+	}{
+		{args: nil, expect: "default"},
+		{args: []string{"kube-proxy", "--v=2"}, expect: "default"},
+		{args: []string{"kube-proxy", "--proxy-mode=iptables"}, expect: "iptables"},
+		{args: []string{"kube-proxy", "--proxy-mode", "userspace"}, expect: "userspace"},
+		{args: []string{"kube-proxy", "--proxy-mode=secret-sauce"}, expect: "other"},
+		{args: []string{"kube-proxy", "--proxy-mode"}, expect: "default"},
+		{ // the mode is read from the config file
+			args:    []string{"kube-proxy", "--config=/var/lib/kube-proxy/config.conf"},
+			configs: []string{"some kubeconfig: {", ipvsConfig},
+			expect:  "ipvs",
+		},
+		{ // the config file takes precedence over flags
+			args:    []string{"kube-proxy", "--config", "/var/lib/kube-proxy/config.conf", "--proxy-mode=iptables"},
+			configs: []string{defaultConfig},
+			expect:  "default",
+		},
+		{ // the config file can not be found
+			args:    []string{"kube-proxy", "--config=/var/lib/kube-proxy/config.conf", "--proxy-mode=iptables"},
+			configs: []string{"kind: Config\n"},
+			expect:  "unknown",
+		},
+	}
+
+	for i, tc := range testCases {
+		if m := proxyModeOf(daemonSet{name: "kube-proxy", args: tc.args, configs: tc.configs}); m != tc.expect {
+			t.Errorf("[%d] expected %q, got %q", i, tc.expect, m)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"encoding/json"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+)
+
+// groupNodes collapses nodes with identical attributes into node groups.
+// Groups are listed in the order of their first node.
+func groupNodes(nodes []report.Node) []report.NodeGroup {
+	var groups []report.NodeGroup
+	index := map[string]int{}
+	for _, n := range nodes {
+		g := report.NodeGroup{
+			OperatingSystem:         n.OperatingSystem,
+			OSImage:                 n.OSImage,
+			KernelVersion:           n.KernelVersion,
+			Architecture:            n.Architecture,
+			ContainerRuntimeVersion: n.ContainerRuntimeVersion,
+			KubeletVersion:          n.KubeletVersion,
+			CloudProvider:           n.CloudProvider,
+			Capacity:                n.Capacity,
+		}
+		// The attributes are plain values and capacity is sorted by
+		// resource, so equal attributes marshal to equal keys.
+		b, err := json.Marshal(g)
+		if err != nil {
+			// This can not happen for these types.
+			panic(err)
+		}
+		key := string(b)
+		if i, found := index[key]; found {
+			groups[i].Count++
+			continue
+		}
+		g.Count = 1
+		index[key] = len(groups)
+		groups = append(groups, g)
+	}
+	return groups
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestGroupNodes(t *testing.T) {
+	cpu4 := []report.Resource{{Resource: "cpu", Value: "4"}}
+	cpu8 := []report.Resource{{Resource: "cpu", Value: "8"}}
+	nodes := []report.Node{
+		{ID: "a", OSImage: strPtr("CoreOS"), KubeletVersion: strPtr("v1.4.6"), Capacity: cpu4, Region: strPtr("us-east-1")},
+		{ID: "b", OSImage: strPtr("CoreOS"), KubeletVersion: strPtr("v1.4.6"), Capacity: cpu8},
+		{ID: "c", OSImage: strPtr("CoreOS"), KubeletVersion: strPtr("v1.4.6"), Capacity: cpu4, Region: strPtr("us-west-1")},
+		{ID: "d", OSImage: strPtr("CoreOS"), KubeletVersion: strPtr("v1.4.5"), Capacity: cpu4},
+		{ID: "e"},
+		{ID: "f", OSImage: strPtr("CoreOS"), KubeletVersion: strPtr("v1.4.6"), Capacity: cpu8},
+	}
+	expect := []report.NodeGroup{
+		{Count: 2, OSImage: strPtr("CoreOS"), KubeletVersion: strPtr("v1.4.6"), Capacity: cpu4},
+		{Count: 2, OSImage: strPtr("CoreOS"), KubeletVersion: strPtr("v1.4.6"), Capacity: cpu8},
+		{Count: 1, OSImage: strPtr("CoreOS"), KubeletVersion: strPtr("v1.4.5"), Capacity: cpu4},
+		{Count: 1},
+	}
+
+	groups := groupNodes(nodes)
+	if !reflect.DeepEqual(groups, expect) {
+		t.Errorf("did not get expected result:\n%s", pretty.Compare(groups, expect))
+	}
+	if groups := groupNodes(nil); groups != nil {
+		t.Errorf("expected no groups, got %v", groups)
+	}
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	kv1 "k8s.io/client-go/1.5/pkg/api/v1"
+)
+
+// podObjects is what we keep of the pods: their security settings and the
+// images of their containers.  The pods are listed once per report, and both
+// are read from the same list.
+type podObjects struct {
+	security []podSecurity
+	// images are the image of every container of every pod.  They are only
+	// classified, never reported.
+	images []string
+}
+
+type podLister interface {
+	ListPods() (podObjects, error)
+}
+
+// podObjectsFromKubePods keeps what we report of the pods.
+func podObjectsFromKubePods(kpods []kv1.Pod) podObjects {
+	objs := podObjects{
+		security: make([]podSecurity, len(kpods)),
+		images:   imagesFromKubePods(kpods),
+	}
+	for i := range kpods {
+		objs.security[i] = podSecurityFromKubePod(&kpods[i])
+	}
+	return objs
+}
+
+// allContainers returns the init containers and the containers of a pod.
+func allContainers(kp *kv1.Pod) []kv1.Container {
+	containers := make([]kv1.Container, 0, len(kp.Spec.InitContainers)+len(kp.Spec.Containers))
+	containers = append(containers, kp.Spec.InitContainers...)
+	return append(containers, kp.Spec.Containers...)
+}
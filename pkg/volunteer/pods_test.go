@@ -0,0 +1,41 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	kv1 "k8s.io/client-go/1.5/pkg/api/v1"
+)
+
+func TestPodObjectsFromKubePods(t *testing.T) {
+	kpods := []kv1.Pod{
+		{Spec: kv1.PodSpec{HostNetwork: true, Containers: []kv1.Container{{Name: "a", Image: "nginx"}}}},
+		{Spec: kv1.PodSpec{Containers: []kv1.Container{{Name: "b", Image: "redis"}, {Name: "c", Image: "busybox"}}}},
+	}
+	expect := podObjects{
+		security: []podSecurity{{hostNetwork: true}, {}},
+		images:   []string{"nginx", "redis", "busybox"},
+	}
+
+	objs := podObjectsFromKubePods(kpods)
+	if !reflect.DeepEqual(objs, expect) {
+		t.Errorf("did not get expected result:\n%s", pretty.Compare(objs, expect))
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+	kv1 "k8s.io/client-go/1.5/pkg/api/v1"
+)
+
+// podSecurity is what we report about a pod: which of a few security-relevant
+// settings it uses.  Nothing else about the pod is kept.
+type podSecurity struct {
+	hostNetwork       bool
+	hostPID           bool
+	privileged        bool
+	runAsNonRoot      bool
+	hostPath          bool
+	addedCapabilities bool
+}
+
+// podSecurityFromKubePod inspects the spec of a pod.
+func podSecurityFromKubePod(kp *kv1.Pod) podSecurity {
+	ps := podSecurity{
+		hostNetwork: kp.Spec.HostNetwork,
+		hostPID:     kp.Spec.HostPID,
+	}
+	for i := range kp.Spec.Volumes {
+		if kp.Spec.Volumes[i].HostPath != nil {
+			ps.hostPath = true
+		}
+	}
+
+	podNonRoot := kp.Spec.SecurityContext != nil && isTrue(kp.Spec.SecurityContext.RunAsNonRoot)
+	// A pod runs as non-root if all of its containers, including its init
+	// containers, do, whether they say so themselves or inherit it from the
+	// pod.
+	containers := allContainers(kp)
+	ps.runAsNonRoot = len(containers) > 0
+	for i := range containers {
+		sc := containers[i].SecurityContext
+		nonRoot := podNonRoot
+		if sc != nil {
+			if sc.RunAsNonRoot != nil {
+				nonRoot = *sc.RunAsNonRoot
+			}
+			if isTrue(sc.Privileged) {
+				ps.privileged = true
+			}
+			if sc.Capabilities != nil && len(sc.Capabilities.Add) > 0 {
+				ps.addedCapabilities = true
+			}
+		}
+		if !nonRoot {
+			ps.runAsNonRoot = false
+		}
+	}
+	return ps
+}
+
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
+
+// podSecurityFromList counts the pods that use each setting.
+func podSecurityFromList(pods []podSecurity) *report.PodSecurity {
+	ps := &report.PodSecurity{PodCount: int64(len(pods))}
+	for _, p := range pods {
+		if p.hostNetwork {
+			ps.HostNetwork++
+		}
+		if p.hostPID {
+			ps.HostPID++
+		}
+		if p.privileged {
+			ps.Privileged++
+		}
+		if p.runAsNonRoot {
+			ps.RunAsNonRoot++
+		}
+		if p.hostPath {
+			ps.HostPathVolumes++
+		}
+		if p.addedCapabilities {
+			ps.AddedCapabilities++
+		}
+	}
+	return ps
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+	"github.com/kylelemons/godebug/pretty"
+	kv1 "k8s.io/client-go/1.5/pkg/api/v1"
+)
+
+func TestPodSecurityFromKubePod(t *testing.T) {
+	testCases := []struct {
+		spec   kv1.PodSpec
+		expect podSecurity
+	}{
+		{
+			spec:   kv1.PodSpec{},
+			expect: podSecurity{},
+		},
+		{
+			spec:   kv1.PodSpec{Containers: []kv1.Container{{Name: "a"}}},
+			expect: podSecurity{},
+		},
+		{
+			spec: kv1.PodSpec{
+				HostNetwork: true,
+				HostPID:     true,
+				Volumes: []kv1.Volume{
+					{Name: "tmp", VolumeSource: kv1.VolumeSource{EmptyDir: &kv1.EmptyDirVolumeSource{}}},
+					{Name: "root", VolumeSource: kv1.VolumeSource{HostPath: &kv1.HostPathVolumeSource{Path: "/"}}},
+				},
+				Containers: []kv1.Container{
+					{Name: "a", SecurityContext: &kv1.SecurityContext{Privileged: boolPtr(true)}},
+					{Name: "b", SecurityContext: &kv1.SecurityContext{
+						Capabilities: &kv1.Capabilities{Add: []kv1.Capability{"NET_ADMIN"}},
+					}},
+				},
+			},
+			expect: podSecurity{hostNetwork: true, hostPID: true, privileged: true, hostPath: true, addedCapabilities: true},
+		},
+		{
+			spec: kv1.PodSpec{
+				Containers: []kv1.Container{
+					{Name: "a", SecurityContext: &kv1.SecurityContext{
+						Privileged:   boolPtr(false),
+						Capabilities: &kv1.Capabilities{Drop: []kv1.Capability{"ALL"}},
+					}},
+				},
+			},
+			expect: podSecurity{},
+		},
+		{ // non-root inherited from the pod
+			spec: kv1.PodSpec{
+				SecurityContext: &kv1.PodSecurityContext{RunAsNonRoot: boolPtr(true)},
+				Containers:      []kv1.Container{{Name: "a"}, {Name: "b", SecurityContext: &kv1.SecurityContext{}}},
+			},
+			expect: podSecurity{runAsNonRoot: true},
+		},
+		{ // non-root overridden by a container
+			spec: kv1.PodSpec{
+				SecurityContext: &kv1.PodSecurityContext{RunAsNonRoot: boolPtr(true)},
+				Containers: []kv1.Container{
+					{Name: "a"},
+					{Name: "b", SecurityContext: &kv1.SecurityContext{RunAsNonRoot: boolPtr(false)}},
+				},
+			},
+			expect: podSecurity{},
+		},
+		{ // non-root set by every container
+			spec: kv1.PodSpec{
+				Containers: []kv1.Container{
+					{Name: "a", SecurityContext: &kv1.SecurityContext{RunAsNonRoot: boolPtr(true)}},
+					{Name: "b", SecurityContext: &kv1.SecurityContext{RunAsNonRoot: boolPtr(true)}},
+				},
+			},
+			expect: podSecurity{runAsNonRoot: true},
+		},
+		{ // init containers are inspected too
+			spec: kv1.PodSpec{
+				InitContainers: []kv1.Container{
+					{Name: "init-a", SecurityContext: &kv1.SecurityContext{Privileged: boolPtr(true)}},
+					{Name: "init-b", SecurityContext: &kv1.SecurityContext{
+						Capabilities: &kv1.Capabilities{Add: []kv1.Capability{"SYS_ADMIN"}},
+					}},
+				},
+				Containers: []kv1.Container{
+					{Name: "a", SecurityContext: &kv1.SecurityContext{RunAsNonRoot: boolPtr(true)}},
+				},
+			},
+			expect: podSecurity{privileged: true, addedCapabilities: true},
+		},
+	}
+
+	for i, tc := range testCases {
+		ps := podSecurityFromKubePod(&kv1.Pod{Spec: tc.spec})
+		if ps != tc.expect {
+			t.Errorf("[%d] expected %+v, got %+v", i, tc.expect, ps)
+		}
+	}
+}
+
+func TestPodSecurityFromList(t *testing.T) {
+	pods := []podSecurity{
+		{hostNetwork: true, hostPID: true},
+		{privileged: true, hostPath: true, addedCapabilities: true},
+		{runAsNonRoot: true},
+		{hostNetwork: true, runAsNonRoot: true},
+		{},
+	}
+	expect := &report.PodSecurity{
+		PodCount:          5,
+		HostNetwork:       2,
+		HostPID:           1,
+		Privileged:        1,
+		RunAsNonRoot:      2,
+		HostPathVolumes:   1,
+		AddedCapabilities: 1,
+	}
+
+	ps := podSecurityFromList(pods)
+	if !reflect.DeepEqual(ps, expect) {
+		t.Errorf("did not get expected result:\n%s", pretty.Compare(ps, expect))
+	}
+}
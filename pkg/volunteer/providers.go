@@ -0,0 +1,117 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// cloudProviderRules is a table of rules for detecting the cloud provider of
+// a node.  It can be loaded from a JSON file, so that providers can be added
+// without changing the volunteer.
+type cloudProviderRules struct {
+	// Rules are tried in order: first against the scheme of the node's
+	// provider ID, and only then against its labels and annotations.
+	Rules []cloudProviderRule `json:"rules"`
+}
+
+type cloudProviderRule struct {
+	// Name is the name reported for the provider.
+	Name string `json:"name"`
+	// ProviderIDs are the schemes of the provider IDs that the provider's
+	// cloud controller sets, e.g. "aws" for "aws:///us-east-1a/i-0123".
+	ProviderIDs []string `json:"providerIDs,omitempty"`
+	// NodeLabels are labels that the provider sets on its nodes, either as
+	// "key", which matches any value, or as "key=value".
+	NodeLabels []string `json:"nodeLabels,omitempty"`
+	// Annotations are node annotations that the provider's instance metadata
+	// agents set, in the same form as NodeLabels.
+	Annotations []string `json:"annotations,omitempty"`
+}
+
+// unknownCloudProvider is reported for nodes that match no rule and have no
+// provider ID to hash.
+const unknownCloudProvider = "unknown"
+
+// defaultCloudProviderRules are used unless rules are loaded from a file.
+var defaultCloudProviderRules = cloudProviderRules{
+	Rules: []cloudProviderRule{
+		{Name: "alicloud", ProviderIDs: []string{"alicloud"}, NodeLabels: []string{"alibabacloud.com/nodepool-id"}},
+		{Name: "aws", ProviderIDs: []string{"aws"}},
+		{Name: "azure", ProviderIDs: []string{"azure"}},
+		{Name: "cloudstack", ProviderIDs: []string{"cloudstack"}},
+		{Name: "digitalocean", ProviderIDs: []string{"digitalocean"}, NodeLabels: []string{"doks.digitalocean.com/node-id"}},
+		{Name: "equinix", ProviderIDs: []string{"equinixmetal", "packet"}},
+		{Name: "gce", ProviderIDs: []string{"gce"}},
+		{Name: "hetzner", ProviderIDs: []string{"hcloud"}},
+		{Name: "ibm", ProviderIDs: []string{"ibm"}, NodeLabels: []string{"ibm-cloud.kubernetes.io/worker-id"}},
+		{Name: "k3s", ProviderIDs: []string{"k3s"}, Annotations: []string{"k3s.io/node-args"}},
+		{Name: "kind", ProviderIDs: []string{"kind"}},
+		{Name: "kubevirt", ProviderIDs: []string{"kubevirt"}},
+		{Name: "linode", ProviderIDs: []string{"linode"}},
+		{Name: "mesos", ProviderIDs: []string{"mesos"}},
+		{Name: "openstack", ProviderIDs: []string{"openstack"}},
+		{Name: "oracle", ProviderIDs: []string{"oci"}},
+		{Name: "ovirt", ProviderIDs: []string{"ovirt"}},
+		{Name: "photon", ProviderIDs: []string{"photon"}},
+		{Name: "rackspace", ProviderIDs: []string{"rackspace"}},
+		{Name: "vsphere", ProviderIDs: []string{"vsphere"}},
+		{Name: "vultr", ProviderIDs: []string{"vultr"}},
+	},
+}
+
+// validate checks that every rule has a name and at least one signal.
+func (rules *cloudProviderRules) validate() error {
+	for i, r := range rules.Rules {
+		if r.Name == "" {
+			return fmt.Errorf("rules[%d].name is required", i)
+		}
+		if len(r.ProviderIDs)+len(r.NodeLabels)+len(r.Annotations) == 0 {
+			return fmt.Errorf("rules[%d] has no signals", i)
+		}
+	}
+	return nil
+}
+
+// providerName returns the cloud provider of a node.  A provider ID has the
+// form <ProviderName>://<ProviderSpecficNodeID> (see
+// https://github.com/kubernetes/client-go/blob/v1.5.1/1.5/pkg/api/v1/types.go#L2446).
+// If no rule matches, the <ProviderName> is hashed, or "unknown" is returned
+// if there is none.
+func providerName(rules cloudProviderRules, providerID string, labels, annotations map[string]string) string {
+	scheme := ""
+	if parts := strings.SplitN(providerID, "://", 2); len(parts) == 2 {
+		scheme = parts[0]
+	}
+	if scheme != "" {
+		for _, r := range rules.Rules {
+			if isOneOf(scheme, r.ProviderIDs) {
+				return r.Name
+			}
+		}
+	}
+	for _, r := range rules.Rules {
+		if hasAnyLabel(labels, r.NodeLabels) || hasAnyLabel(annotations, r.Annotations) {
+			return r.Name
+		}
+	}
+	if scheme != "" {
+		return hashOf(scheme)
+	}
+	return unknownCloudProvider
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestProviderName(t *testing.T) {
+	testCases := []struct {
+		providerID  string
+		labels      map[string]string
+		annotations map[string]string
+		expect      string
+	}{
+		{providerID: "", expect: "unknown"},
+		{providerID: "i-0123", expect: "unknown"},
+		{providerID: "aws:///us-east-1a/i-0123", expect: "aws"},
+		{providerID: "gce://project/us-central1-a/node-1", expect: "gce"},
+		{providerID: "digitalocean://1234", expect: "digitalocean"},
+		{providerID: "packet://1234", expect: "equinix"},
+		{providerID: "kind://docker/kind/kind-control-plane", expect: "kind"},
+		{providerID: "foo://bar", expect: hashOf("foo")},
+		{ // labels identify providers whose IDs have no scheme
+			providerID: "cn-hangzhou.i-0123",
+			labels:     map[string]string{"alibabacloud.com/nodepool-id": "np-1"},
+			expect:     "alicloud",
+		},
+		{
+			annotations: map[string]string{"k3s.io/node-args": "[]"},
+			expect:      "k3s",
+		},
+		{ // the provider ID wins over labels and annotations
+			providerID:  "aws:///us-east-1a/i-0123",
+			annotations: map[string]string{"k3s.io/node-args": "[]"},
+			expect:      "aws",
+		},
+		{ // labels win over hashing
+			providerID: "foo://bar",
+			labels:     map[string]string{"ibm-cloud.kubernetes.io/worker-id": "kube-1"},
+			expect:     "ibm",
+		},
+	}
+
+	for i, tc := range testCases {
+		name := providerName(defaultCloudProviderRules, tc.providerID, tc.labels, tc.annotations)
+		if name != tc.expect {
+			t.Errorf("[%d] expected %q, got %q", i, tc.expect, name)
+		}
+	}
+}
+
+func TestParseCloudProviderRules(t *testing.T) {
+	testCases := []struct {
+		input  string
+		errstr string
+		expect cloudProviderRules
+	}{
+		{
+			input: `{"rules": [{"name": "acme", "providerIDs": ["acme"], "annotations": ["acme.example.com/instance-id"]}]}`,
+			expect: cloudProviderRules{
+				Rules: []cloudProviderRule{
+					{Name: "acme", ProviderIDs: []string{"acme"}, Annotations: []string{"acme.example.com/instance-id"}},
+				},
+			},
+		},
+		{
+			input:  `{"rules": [{"providerIDs": ["acme"]}]}`,
+			errstr: "rules[0].name is required",
+		},
+		{
+			input:  `{"rules": [{"name": "acme"}]}`,
+			errstr: "rules[0] has no signals",
+		},
+		{
+			input:  `{"rules": {}}`,
+			errstr: "failed to parse",
+		},
+	}
+
+	for i, tc := range testCases {
+		var rules cloudProviderRules
+		err := parseRules([]byte(tc.input), "cloud provider", &rules)
+		if err != nil && tc.errstr == "" {
+			t.Errorf("[%d] unexpected error %q", i, err)
+		} else if err != nil {
+			if !strings.Contains(err.Error(), tc.errstr) {
+				t.Errorf("[%d] expected error containing %q, got %q", i, tc.errstr, err)
+			}
+		} else if err == nil && tc.errstr != "" {
+			t.Errorf("[%d] expected error containing %q: no error", i, tc.errstr)
+		} else if !reflect.DeepEqual(rules, tc.expect) {
+			t.Errorf("[%d] did not get expected result:\n%s", i, pretty.Compare(rules, tc.expect))
+		}
+	}
+}
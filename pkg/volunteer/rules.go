@@ -0,0 +1,51 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// ruleSet is a set of detection rules, such as distributionRules, that can
+// be read from a JSON file to replace the built-in rules.
+type ruleSet interface {
+	// validate checks the rules after they are parsed.
+	validate() error
+}
+
+// loadRules reads a set of rules of a kind, e.g. "distribution", from a
+// JSON file.
+func loadRules(path, kind string, rules ruleSet) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s rules file: %v", kind, err)
+	}
+	return parseRules(b, kind, rules)
+}
+
+// parseRules parses and checks a set of rules of a kind in JSON.
+func parseRules(b []byte, kind string, rules ruleSet) error {
+	if err := json.Unmarshal(b, rules); err != nil {
+		return fmt.Errorf("failed to parse %s rules: %v", kind, err)
+	}
+	if err := rules.validate(); err != nil {
+		return fmt.Errorf("invalid %s rules: %v", kind, err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestLoadRules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rules")
+	if err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	valid := filepath.Join(dir, "valid.json")
+	if err := ioutil.WriteFile(valid, []byte(`{"version": "v1", "rules": [{"name": "acme", "versions": ["-acme"]}]}`), 0644); err != nil {
+		t.Fatalf("failed to write rules: %v", err)
+	}
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := ioutil.WriteFile(invalid, []byte(`{"rules": [{"name": "acme"}]}`), 0644); err != nil {
+		t.Fatalf("failed to write rules: %v", err)
+	}
+
+	testCases := []struct {
+		path   string
+		expect distributionRules
+		errstr string
+	}{
+		{
+			path: valid,
+			expect: distributionRules{
+				Version: "v1",
+				Rules:   []distributionRule{{Name: "acme", Versions: []string{"-acme"}}},
+			},
+		},
+		{path: invalid, errstr: "invalid distribution rules: version is required"},
+		{path: filepath.Join(dir, "missing.json"), errstr: "failed to read distribution rules file"},
+	}
+	for i, tc := range testCases {
+		var rules distributionRules
+		err := loadRules(tc.path, "distribution", &rules)
+		if err != nil && tc.errstr == "" {
+			t.Errorf("[%d] unexpected error %q", i, err)
+		} else if err == nil && tc.errstr != "" {
+			t.Errorf("[%d] expected error %q, got nil", i, tc.errstr)
+		} else if err != nil && !strings.Contains(err.Error(), tc.errstr) {
+			t.Errorf("[%d] expected error %q, got %q", i, tc.errstr, err)
+		} else if err == nil && !reflect.DeepEqual(rules, tc.expect) {
+			t.Errorf("[%d] did not get expected rules:\n%s", i, pretty.Compare(rules, tc.expect))
+		}
+	}
+}
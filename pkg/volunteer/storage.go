@@ -0,0 +1,133 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"strings"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+)
+
+// storageClass is what we report about a StorageClass.  Its name is
+// deliberately not kept - it is chosen by the user.
+type storageClass struct {
+	provisioner string
+	isDefault   bool
+}
+
+// volume is what we report about a PersistentVolume or a
+// PersistentVolumeClaim.
+type volume struct {
+	accessModes []string
+	// reclaimPolicy is empty for claims.
+	reclaimPolicy string
+	// bytes is the capacity of a volume, or the storage requested by a claim.
+	bytes int64
+}
+
+type storageObjects struct {
+	classes []storageClass
+	volumes []volume
+	claims  []volume
+}
+
+type storageLister interface {
+	ListStorage() (storageObjects, error)
+}
+
+// inTreeProvisionerPrefix is the prefix of the provisioners that are built
+// into kubernetes, such as "kubernetes.io/aws-ebs".
+const inTreeProvisionerPrefix = "kubernetes.io/"
+
+// knownProvisioners are the out-of-tree provisioners that are reported
+// verbatim.  Anything else might be a name chosen by the user, and is hashed.
+var knownProvisioners = map[string]bool{
+	"cephfs.csi.ceph.com":          true,
+	"csi.trident.netapp.io":        true,
+	"csi.vsphere.vmware.com":       true,
+	"disk.csi.azure.com":           true,
+	"driver.longhorn.io":           true,
+	"ebs.csi.aws.com":              true,
+	"efs.csi.aws.com":              true,
+	"file.csi.azure.com":           true,
+	"filestore.csi.storage.gke.io": true,
+	"hostpath.csi.k8s.io":          true,
+	"openebs.io/local":             true,
+	"pd.csi.storage.gke.io":        true,
+	"rancher.io/local-path":        true,
+	"rbd.csi.ceph.com":             true,
+}
+
+// capacityBoundsGiB are the inclusive upper bounds of the capacity histogram
+// buckets, in GiB.  The last bucket is unbounded.
+var capacityBoundsGiB = []int64{1, 5, 10, 20, 50, 100, 200, 500, 1000}
+
+const bytesPerGiB = 1 << 30
+
+// provisionerName returns the provisioner if it is in-tree or well-known, or
+// its hash otherwise.
+func provisionerName(p string) string {
+	if strings.HasPrefix(p, inTreeProvisionerPrefix) || knownProvisioners[p] {
+		return p
+	}
+	return hashOf(p)
+}
+
+// storageFromObjects builds the report section for the storage objects.
+func storageFromObjects(objs storageObjects) *report.Storage {
+	st := &report.Storage{
+		ClassCount: int64(len(objs.classes)),
+	}
+	provisioners := map[string]int64{}
+	var defaultProvisioner string
+	for _, c := range objs.classes {
+		name := provisionerName(c.provisioner)
+		provisioners[name]++
+		if c.isDefault {
+			st.DefaultClassCount++
+			defaultProvisioner = name
+		}
+	}
+	st.Provisioners = valueCounts(provisioners)
+	if st.DefaultClassCount == 1 {
+		st.DefaultProvisioner = strPtr(defaultProvisioner)
+	}
+	st.PersistentVolumes = volumesFromList(objs.volumes)
+	st.PersistentVolumeClaims = volumesFromList(objs.claims)
+	return st
+}
+
+func volumesFromList(vols []volume) *report.Volumes {
+	v := &report.Volumes{Count: int64(len(vols))}
+	modes := map[string]int64{}
+	policies := map[string]int64{}
+	sizes := make([]int64, len(vols))
+	for i, vol := range vols {
+		for _, m := range vol.accessModes {
+			modes[m]++
+		}
+		if vol.reclaimPolicy != "" {
+			policies[vol.reclaimPolicy]++
+		}
+		// Round up, so that no volume is larger than its bucket says.
+		sizes[i] = (vol.bytes + bytesPerGiB - 1) / bytesPerGiB
+	}
+	v.AccessModes = valueCounts(modes)
+	v.ReclaimPolicies = valueCounts(policies)
+	v.CapacityGiB = makeBuckets(capacityBoundsGiB, sizes)
+	return v
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestProvisionerName(t *testing.T) {
+	testCases := []struct {
+		provisioner string
+		expect      string
+	}{
+		{"kubernetes.io/aws-ebs", "kubernetes.io/aws-ebs"},
+		{"kubernetes.io/glusterfs", "kubernetes.io/glusterfs"},
+		{"ebs.csi.aws.com", "ebs.csi.aws.com"},
+		{"example.com/nfs", hashOf("example.com/nfs")},
+		{"", hashOf("")},
+	}
+
+	for i, tc := range testCases {
+		if name := provisionerName(tc.provisioner); name != tc.expect {
+			t.Errorf("[%d] expected %q, got %q", i, tc.expect, name)
+		}
+	}
+}
+
+func TestStorageFromObjects(t *testing.T) {
+	rwo := []string{"ReadWriteOnce"}
+	objs := storageObjects{
+		classes: []storageClass{
+			{provisioner: "kubernetes.io/gce-pd", isDefault: true},
+			{provisioner: "kubernetes.io/gce-pd"},
+			{provisioner: "example.com/nfs"},
+		},
+		volumes: []volume{
+			{accessModes: rwo, reclaimPolicy: "Delete", bytes: 10 * bytesPerGiB},
+			{accessModes: []string{"ReadWriteOnce", "ReadOnlyMany"}, reclaimPolicy: "Retain", bytes: 10*bytesPerGiB + 1},
+			{accessModes: rwo, reclaimPolicy: "Delete", bytes: 2000 * bytesPerGiB},
+		},
+		claims: []volume{
+			{accessModes: rwo, bytes: 1 << 20},
+			{accessModes: rwo},
+		},
+	}
+	expect := &report.Storage{
+		ClassCount: 3,
+		Provisioners: []report.ValueCount{
+			{Value: hashOf("example.com/nfs"), Count: 1},
+			{Value: "kubernetes.io/gce-pd", Count: 2},
+		},
+		DefaultClassCount:  1,
+		DefaultProvisioner: strPtr("kubernetes.io/gce-pd"),
+		PersistentVolumes: &report.Volumes{
+			Count: 3,
+			AccessModes: []report.ValueCount{
+				{Value: "ReadOnlyMany", Count: 1},
+				{Value: "ReadWriteOnce", Count: 3},
+			},
+			ReclaimPolicies: []report.ValueCount{
+				{Value: "Delete", Count: 2},
+				{Value: "Retain", Count: 1},
+			},
+			CapacityGiB: []report.Bucket{
+				{Min: 6, Max: int64Ptr(10), Count: 1},
+				{Min: 11, Max: int64Ptr(20), Count: 1},
+				{Min: 1001, Count: 1},
+			},
+		},
+		PersistentVolumeClaims: &report.Volumes{
+			Count:       2,
+			AccessModes: []report.ValueCount{{Value: "ReadWriteOnce", Count: 2}},
+			CapacityGiB: []report.Bucket{{Min: 0, Max: int64Ptr(1), Count: 2}},
+		},
+	}
+
+	st := storageFromObjects(objs)
+	if !reflect.DeepEqual(st, expect) {
+		t.Errorf("did not get expected result:\n%s", pretty.Compare(st, expect))
+	}
+
+	// With more than one default, there is no default provisioner.
+	objs.classes[1].isDefault = true
+	if st := storageFromObjects(objs); st.DefaultClassCount != 2 || st.DefaultProvisioner != nil {
+		t.Errorf("expected 2 default classes and no default provisioner, got %d and %v", st.DefaultClassCount, st.DefaultProvisioner)
+	}
+}
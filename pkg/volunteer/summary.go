@@ -0,0 +1,82 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"sort"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+	kresource "k8s.io/client-go/1.5/pkg/api/resource"
+	kv1 "k8s.io/client-go/1.5/pkg/api/v1"
+)
+
+// summaryFromNodes adds up the capacity of the nodes and counts their
+// distinct kubelet versions, OS images and architectures.
+func summaryFromNodes(nodes []report.Node) *report.Summary {
+	sum := &report.Summary{
+		NodeCount: int64(len(nodes)),
+	}
+	kubelets := map[string]int64{}
+	images := map[string]int64{}
+	archs := map[string]int64{}
+	for _, n := range nodes {
+		for _, r := range n.Capacity {
+			q, err := kresource.ParseQuantity(r.Value)
+			if err != nil {
+				// The values were formatted from quantities, so this is
+				// not expected; leave them out of the totals.
+				continue
+			}
+			switch kv1.ResourceName(r.Resource) {
+			case kv1.ResourceCPU:
+				sum.CPUCapacityMillis += q.MilliValue()
+			case kv1.ResourceMemory:
+				sum.MemoryCapacityBytes += q.Value()
+			case kv1.ResourcePods:
+				sum.PodsCapacity += q.Value()
+			}
+		}
+		if n.KubeletVersion != nil {
+			kubelets[*n.KubeletVersion]++
+		}
+		if n.OSImage != nil {
+			images[*n.OSImage]++
+		}
+		if n.Architecture != nil {
+			archs[*n.Architecture]++
+		}
+	}
+	sum.KubeletVersions = valueCounts(kubelets)
+	sum.OSImages = valueCounts(images)
+	sum.Architectures = valueCounts(archs)
+	return sum
+}
+
+// valueCounts converts a map of counts into a list sorted by value.
+func valueCounts(counts map[string]int64) []report.ValueCount {
+	// We want to report the values in a deterministic order.
+	keys := []string{}
+	for v := range counts {
+		keys = append(keys, v)
+	}
+	sort.Strings(keys)
+	var vcs []report.ValueCount
+	for _, v := range keys {
+		vcs = append(vcs, report.ValueCount{Value: v, Count: counts[v]})
+	}
+	return vcs
+}
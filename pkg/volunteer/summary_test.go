@@ -0,0 +1,86 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestSummaryFromNodes(t *testing.T) {
+	nodes := []report.Node{
+		{
+			ID:             "a",
+			OSImage:        strPtr("Debian GNU/Linux 7 (wheezy)"),
+			Architecture:   strPtr("amd64"),
+			KubeletVersion: strPtr("v1.4.6"),
+			Capacity: []report.Resource{
+				{Resource: "cpu", Value: "2"},
+				{Resource: "memory", Value: "1Gi"},
+				{Resource: "pods", Value: "110"},
+				{Resource: "alpha.kubernetes.io/nvidia-gpu", Value: "1"},
+			},
+		},
+		{
+			ID:             "b",
+			OSImage:        strPtr("Debian GNU/Linux 7 (wheezy)"),
+			Architecture:   strPtr("arm64"),
+			KubeletVersion: strPtr("v1.4.5"),
+			Capacity: []report.Resource{
+				{Resource: "cpu", Value: "500m"},
+				{Resource: "memory", Value: "1024Mi"},
+				{Resource: "pods", Value: "40"},
+			},
+		},
+		{
+			ID:             "c",
+			Architecture:   strPtr("amd64"),
+			KubeletVersion: strPtr("v1.4.6"),
+			Capacity: []report.Resource{
+				{Resource: "cpu", Value: "not a quantity"},
+			},
+		},
+	}
+	expect := &report.Summary{
+		NodeCount:           3,
+		CPUCapacityMillis:   2500,
+		MemoryCapacityBytes: 2 << 30,
+		PodsCapacity:        150,
+		KubeletVersions: []report.ValueCount{
+			{Value: "v1.4.5", Count: 1},
+			{Value: "v1.4.6", Count: 2},
+		},
+		OSImages: []report.ValueCount{
+			{Value: "Debian GNU/Linux 7 (wheezy)", Count: 2},
+		},
+		Architectures: []report.ValueCount{
+			{Value: "amd64", Count: 2},
+			{Value: "arm64", Count: 1},
+		},
+	}
+
+	sum := summaryFromNodes(nodes)
+	if !reflect.DeepEqual(sum, expect) {
+		t.Errorf("did not get expected result:\n%s", pretty.Compare(sum, expect))
+	}
+	if sum := summaryFromNodes(nil); !reflect.DeepEqual(sum, &report.Summary{}) {
+		t.Errorf("expected empty summary, got %v", sum)
+	}
+}
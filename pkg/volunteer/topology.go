@@ -0,0 +1,182 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+	kunversioned "k8s.io/client-go/1.5/pkg/api/unversioned"
+	kv1 "k8s.io/client-go/1.5/pkg/api/v1"
+)
+
+const (
+	// nodeRoleLabelPrefix is the prefix of the node-role.kubernetes.io/<role>
+	// labels.
+	nodeRoleLabelPrefix = "node-role.kubernetes.io/"
+	// nodeRoleLabel is the older kubernetes.io/role=<role> label.
+	nodeRoleLabel = "kubernetes.io/role"
+	// otherRole is reported for roles that are not well-known.
+	otherRole = "other"
+
+	// regionLabel, zoneLabel and instanceTypeLabel are the GA topology
+	// labels.  Older versions of kubernetes only set the beta labels in
+	// kunversioned.
+	regionLabel       = "topology.kubernetes.io/region"
+	zoneLabel         = "topology.kubernetes.io/zone"
+	instanceTypeLabel = "node.kubernetes.io/instance-type"
+)
+
+// awsRegion and gceRegion match the names of the regions of AWS and GCE,
+// which are made of a geography, a direction and a number.  Only the
+// geographies and directions that the clouds use are matched, so that names
+// chosen by the user are not mistaken for them.
+const (
+	awsRegion = `(us|eu|ap|sa|ca|me|af|il|mx|cn)(-gov|-iso|-isob)?-(north|south|east|west|central|northeast|southeast|northwest|southwest)-[0-9]`
+	gceRegion = `(us|northamerica|southamerica|europe|asia|australia|me|africa)-(north|south|east|west|central|northeast|southeast|northwest|southwest)[0-9]`
+)
+
+// knownRegions, knownZones and knownInstanceTypes match the label values of
+// the major clouds, which are safe to report verbatim.  Anything else might
+// be a name chosen by the user, and is hashed.
+var (
+	knownRegions = []*regexp.Regexp{
+		// AWS, e.g. "us-east-1" or "us-gov-west-1".
+		regexp.MustCompile(`^` + awsRegion + `$`),
+		// GCE, e.g. "us-central1".
+		regexp.MustCompile(`^` + gceRegion + `$`),
+		// Azure, e.g. "westeurope".
+		regexp.MustCompile(`^(east|west|central|north|south|northcentral|southcentral|westcentral)(us|us2|us3|europe|asia|india|japan)$`),
+	}
+	knownZones = []*regexp.Regexp{
+		// AWS, e.g. "us-east-1a".
+		regexp.MustCompile(`^` + awsRegion + `[a-z]$`),
+		// GCE, e.g. "us-central1-b".
+		regexp.MustCompile(`^` + gceRegion + `-[a-z]$`),
+		// Azure fault domains, e.g. "0".
+		regexp.MustCompile(`^[0-9]$`),
+	}
+	knownInstanceTypes = []*regexp.Regexp{
+		// AWS, e.g. "m4.large" or "c5d.18xlarge".
+		regexp.MustCompile(`^[a-z][a-z0-9-]*\.([0-9]*x)?(nano|micro|small|medium|large|xlarge|metal)$`),
+		// GCE, e.g. "n1-standard-4" or "custom-2-7680".
+		regexp.MustCompile(`^([a-z][0-9]-(standard|highmem|highcpu|micro|small|megamem|ultramem)(-[0-9]+)?|custom-[0-9]+-[0-9]+)$`),
+		// Azure, e.g. "Standard_D2_v2".
+		regexp.MustCompile(`^(Standard|Basic)_[A-Za-z0-9_]+$`),
+	}
+)
+
+// knownRoles are the node roles that are reported verbatim.
+var knownRoles = map[string]bool{
+	"control-plane": true,
+	"etcd":          true,
+	"infra":         true,
+	"ingress":       true,
+	"master":        true,
+	"node":          true,
+	"worker":        true,
+}
+
+// knownOrHashed returns value if it matches one of the patterns, or its hash
+// otherwise.
+func knownOrHashed(value string, patterns []*regexp.Regexp) *string {
+	if value == "" {
+		return nil
+	}
+	for _, re := range patterns {
+		if re.MatchString(value) {
+			return strPtr(value)
+		}
+	}
+	return strPtr(hashOf(value))
+}
+
+// setTopology fills in the region, zone, instance type and roles of a node
+// from its well-known labels.  The GA labels are preferred, and the beta
+// labels are read from nodes that do not have them.
+func setTopology(n *report.Node, kn *kv1.Node) {
+	n.Region = knownOrHashed(firstLabel(kn.Labels, regionLabel, kunversioned.LabelZoneRegion), knownRegions)
+	n.Zone = knownOrHashed(firstLabel(kn.Labels, zoneLabel, kunversioned.LabelZoneFailureDomain), knownZones)
+	n.InstanceType = knownOrHashed(firstLabel(kn.Labels, instanceTypeLabel, kunversioned.LabelInstanceType), knownInstanceTypes)
+
+	roles := map[string]bool{}
+	for k, v := range kn.Labels {
+		role := ""
+		if strings.HasPrefix(k, nodeRoleLabelPrefix) {
+			role = strings.TrimPrefix(k, nodeRoleLabelPrefix)
+		} else if k == nodeRoleLabel {
+			role = v
+		}
+		if role == "" {
+			continue
+		}
+		if !knownRoles[role] {
+			role = otherRole
+		}
+		roles[role] = true
+	}
+	for r := range roles {
+		n.Roles = append(n.Roles, r)
+	}
+	// We want to report the roles in a deterministic order.
+	sort.Strings(n.Roles)
+}
+
+// firstLabel returns the value of the first of the keys that is a label.
+func firstLabel(labels map[string]string, keys ...string) string {
+	for _, k := range keys {
+		if v, found := labels[k]; found {
+			return v
+		}
+	}
+	return ""
+}
+
+// topologyFromNodes summarizes the regions, zones and roles of the nodes.
+func topologyFromNodes(nodes []report.Node) *report.Topology {
+	regions := map[string]bool{}
+	zones := map[string]bool{}
+	roles := map[string]int64{}
+	for _, n := range nodes {
+		if n.Region != nil {
+			regions[*n.Region] = true
+		}
+		if n.Zone != nil {
+			zones[*n.Zone] = true
+		}
+		for _, r := range n.Roles {
+			roles[r]++
+		}
+	}
+
+	topo := &report.Topology{
+		RegionCount: int64(len(regions)),
+		ZoneCount:   int64(len(zones)),
+	}
+	// We want to report the roles in a deterministic order.
+	keys := []string{}
+	for r := range roles {
+		keys = append(keys, r)
+	}
+	sort.Strings(keys)
+	for _, r := range keys {
+		topo.Roles = append(topo.Roles, report.RoleCount{Role: r, Count: roles[r]})
+	}
+	return topo
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+	"github.com/kylelemons/godebug/pretty"
+	kv1 "k8s.io/client-go/1.5/pkg/api/v1"
+)
+
+func TestSetTopology(t *testing.T) {
+	testCases := []struct {
+		labels map[string]string
+		expect report.Node
+	}{
+		{
+			labels: nil,
+			expect: report.Node{},
+		},
+		{
+			labels: map[string]string{
+				"failure-domain.beta.kubernetes.io/region": "us-east-1",
+				"failure-domain.beta.kubernetes.io/zone":   "us-east-1a",
+				"beta.kubernetes.io/instance-type":         "m4.large",
+				"node-role.kubernetes.io/master":           "",
+				"node-role.kubernetes.io/etcd":             "",
+			},
+			expect: report.Node{
+				Region:       strPtr("us-east-1"),
+				Zone:         strPtr("us-east-1a"),
+				InstanceType: strPtr("m4.large"),
+				Roles:        []string{"etcd", "master"},
+			},
+		},
+		{
+			labels: map[string]string{
+				"failure-domain.beta.kubernetes.io/region": "us-central1",
+				"failure-domain.beta.kubernetes.io/zone":   "us-central1-b",
+				"beta.kubernetes.io/instance-type":         "n1-standard-4",
+				"kubernetes.io/role":                       "node",
+			},
+			expect: report.Node{
+				Region:       strPtr("us-central1"),
+				Zone:         strPtr("us-central1-b"),
+				InstanceType: strPtr("n1-standard-4"),
+				Roles:        []string{"node"},
+			},
+		},
+		{
+			labels: map[string]string{
+				"failure-domain.beta.kubernetes.io/region": "building-42",
+				"failure-domain.beta.kubernetes.io/zone":   "rack-7",
+				"beta.kubernetes.io/instance-type":         "big-box",
+				"node-role.kubernetes.io/team-alice":       "",
+				"node-role.kubernetes.io/team-bob":         "",
+				"kubernetes.io/role":                       "worker",
+			},
+			expect: report.Node{
+				Region:       strPtr(hashOf("building-42")),
+				Zone:         strPtr(hashOf("rack-7")),
+				InstanceType: strPtr(hashOf("big-box")),
+				Roles:        []string{"other", "worker"},
+			},
+		},
+		{ // The GA labels win over the beta ones.
+			labels: map[string]string{
+				"topology.kubernetes.io/region":            "europe-west4",
+				"topology.kubernetes.io/zone":              "europe-west4-a",
+				"node.kubernetes.io/instance-type":         "e2-standard-4",
+				"failure-domain.beta.kubernetes.io/region": "us-east-1",
+				"failure-domain.beta.kubernetes.io/zone":   "us-east-1a",
+				"beta.kubernetes.io/instance-type":         "m4.large",
+			},
+			expect: report.Node{
+				Region:       strPtr("europe-west4"),
+				Zone:         strPtr("europe-west4-a"),
+				InstanceType: strPtr("e2-standard-4"),
+			},
+		},
+		{ // Private names that look like cloud regions are hashed.
+			labels: map[string]string{
+				"topology.kubernetes.io/region": "office-floor3",
+				"topology.kubernetes.io/zone":   "my-lab-2b",
+			},
+			expect: report.Node{
+				Region: strPtr(hashOf("office-floor3")),
+				Zone:   strPtr(hashOf("my-lab-2b")),
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		n := report.Node{}
+		setTopology(&n, &kv1.Node{ObjectMeta: kv1.ObjectMeta{Labels: tc.labels}})
+		if !reflect.DeepEqual(n, tc.expect) {
+			t.Errorf("[%d] did not get expected result:\n%s", i, pretty.Compare(n, tc.expect))
+		}
+	}
+}
+
+func TestTopologyFromNodes(t *testing.T) {
+	nodes := []report.Node{
+		{ID: "a", Region: strPtr("us-east-1"), Zone: strPtr("us-east-1a"), Roles: []string{"master"}},
+		{ID: "b", Region: strPtr("us-east-1"), Zone: strPtr("us-east-1b"), Roles: []string{"node"}},
+		{ID: "c", Region: strPtr("us-east-1"), Zone: strPtr("us-east-1b"), Roles: []string{"node"}},
+		{ID: "d"},
+	}
+	expect := &report.Topology{
+		RegionCount: 1,
+		ZoneCount:   2,
+		Roles: []report.RoleCount{
+			{Role: "master", Count: 1},
+			{Role: "node", Count: 2},
+		},
+	}
+
+	topo := topologyFromNodes(nodes)
+	if !reflect.DeepEqual(topo, expect) {
+		t.Errorf("did not get expected result:\n%s", pretty.Compare(topo, expect))
+	}
+	if topo := topologyFromNodes(nil); !reflect.DeepEqual(topo, &report.Topology{}) {
+		t.Errorf("expected empty topology, got %v", topo)
+	}
+}
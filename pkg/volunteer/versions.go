@@ -0,0 +1,80 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/blang/semver"
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+)
+
+// versionRE matches the versions that are almost semantic versions, such as
+// "v1.4.6" or "17.03.1-ce": an optional leading "v", leading zeros, and a
+// missing patch version are tolerated.
+var versionRE = regexp.MustCompile(`^v?0*([0-9]+)\.0*([0-9]+)(?:\.0*([0-9]+))?([-+].*)?$`)
+
+// parseSemver parses a version string, or returns nil if it is not a
+// semantic version.
+func parseSemver(s string) *report.Semver {
+	m := versionRE.FindStringSubmatch(s)
+	if m == nil {
+		return nil
+	}
+	if m[3] == "" {
+		m[3] = "0"
+	}
+	sv, err := semver.Parse(m[1] + "." + m[2] + "." + m[3] + m[4])
+	if err != nil {
+		return nil
+	}
+
+	v := &report.Semver{
+		Major: int64(sv.Major),
+		Minor: int64(sv.Minor),
+		Patch: int64(sv.Patch),
+	}
+	if len(sv.Pre) > 0 {
+		parts := make([]string, len(sv.Pre))
+		for i, p := range sv.Pre {
+			parts[i] = p.String()
+		}
+		v.Prerelease = strPtr(strings.Join(parts, "."))
+	}
+	return v
+}
+
+// parseRuntimeVersion splits a container runtime version, which should match
+// <RuntimeName>://<Version>, into the runtime's name and its parsed version.
+func parseRuntimeVersion(s string) (*string, *report.Semver) {
+	parts := strings.SplitN(s, "://", 2)
+	if len(parts) != 2 {
+		return nil, nil
+	}
+	return strPtr(parts[0]), parseSemver(parts[1])
+}
+
+// setVersions fills in the parsed forms of the node's version strings.
+func setVersions(n *report.Node) {
+	if n.ContainerRuntimeVersion != nil {
+		n.ContainerRuntimeName, n.ContainerRuntimeSemver = parseRuntimeVersion(*n.ContainerRuntimeVersion)
+	}
+	if n.KubeletVersion != nil {
+		n.KubeletSemver = parseSemver(*n.KubeletVersion)
+	}
+}
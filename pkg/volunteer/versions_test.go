@@ -0,0 +1,102 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestSetVersions(t *testing.T) {
+	testCases := []struct {
+		input  report.Node
+		expect report.Node
+	}{
+		{
+			input:  report.Node{},
+			expect: report.Node{},
+		},
+		{
+			input: report.Node{
+				ContainerRuntimeVersion: strPtr("docker://1.11.2"),
+				KubeletVersion:          strPtr("v1.5.0-alpha.2.421+a6bea3d79b8bba"),
+			},
+			expect: report.Node{
+				ContainerRuntimeVersion: strPtr("docker://1.11.2"),
+				KubeletVersion:          strPtr("v1.5.0-alpha.2.421+a6bea3d79b8bba"),
+				ContainerRuntimeName:    strPtr("docker"),
+				ContainerRuntimeSemver:  &report.Semver{Major: 1, Minor: 11, Patch: 2},
+				KubeletSemver:           &report.Semver{Major: 1, Minor: 5, Patch: 0, Prerelease: strPtr("alpha.2.421")},
+			},
+		},
+		{
+			input: report.Node{
+				ContainerRuntimeVersion: strPtr("docker://17.03.1-ce"),
+				KubeletVersion:          strPtr("v1.4.6+e569a27"),
+			},
+			expect: report.Node{
+				ContainerRuntimeVersion: strPtr("docker://17.03.1-ce"),
+				KubeletVersion:          strPtr("v1.4.6+e569a27"),
+				ContainerRuntimeName:    strPtr("docker"),
+				ContainerRuntimeSemver:  &report.Semver{Major: 17, Minor: 3, Patch: 1, Prerelease: strPtr("ce")},
+				KubeletSemver:           &report.Semver{Major: 1, Minor: 4, Patch: 6},
+			},
+		},
+		{
+			input: report.Node{
+				ContainerRuntimeVersion: strPtr("rkt://1.20"),
+			},
+			expect: report.Node{
+				ContainerRuntimeVersion: strPtr("rkt://1.20"),
+				ContainerRuntimeName:    strPtr("rkt"),
+				ContainerRuntimeSemver:  &report.Semver{Major: 1, Minor: 20},
+			},
+		},
+		{
+			input: report.Node{
+				ContainerRuntimeVersion: strPtr("cri-o://unknown"),
+				KubeletVersion:          strPtr("kubelet"),
+			},
+			expect: report.Node{
+				ContainerRuntimeVersion: strPtr("cri-o://unknown"),
+				KubeletVersion:          strPtr("kubelet"),
+				ContainerRuntimeName:    strPtr("cri-o"),
+			},
+		},
+		{
+			input: report.Node{
+				ContainerRuntimeVersion: strPtr("runtime"),
+				KubeletVersion:          strPtr("v1.4.6-"),
+			},
+			expect: report.Node{
+				ContainerRuntimeVersion: strPtr("runtime"),
+				KubeletVersion:          strPtr("v1.4.6-"),
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		n := tc.input
+		setVersions(&n)
+		if !reflect.DeepEqual(n, tc.expect) {
+			t.Errorf("[%d] did not get expected result:\n%s", i, pretty.Compare(n, tc.expect))
+		}
+	}
+}
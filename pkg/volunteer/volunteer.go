@@ -17,11 +17,14 @@ limitations under the License.
 package volunteer
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -32,43 +35,168 @@ import (
 	"github.com/thockin/logr"
 )
 
-func New(log logr.Logger, clusterID string, period time.Duration, db database.Database, extensionsPath string) (*volunteer, error) {
+// ExtensionsConfig says where the volunteer reads extensions from, and how
+// it treats problems with them.
+type ExtensionsConfig struct {
+	// Path is a file or directory of extensions files, or empty to read no
+	// files.
+	Path string
+	// ConfigMapSelector is a label selector for the ConfigMaps whose data
+	// are extensions, or empty to read no ConfigMaps.
+	ConfigMapSelector string
+	// AnnotationPrefix is the prefix of the annotations of the kube-system
+	// namespace that are extensions, or empty to read no annotations.
+	AnnotationPrefix string
+	// MetricsEndpoints are OpenMetrics or Prometheus endpoints to scrape
+	// for extensions, or empty to scrape none.
+	MetricsEndpoints []string
+	// MetricsAllowlist is the names of the metrics that are reported from
+	// MetricsEndpoints.
+	MetricsAllowlist []string
+	// MetricsTimeout is how long scraping each endpoint may take.
+	MetricsTimeout time.Duration
+	// MetricsInClusterAuth makes scrapes of https MetricsEndpoints send the
+	// token of the pod's service account, and trust the cluster's CA.
+	MetricsInClusterAuth bool
+	// HooksDir is a directory of executables that print extensions, or
+	// empty to run no hooks.
+	HooksDir string
+	// HookTimeout is how long each hook may run before it is killed.
+	HookTimeout time.Duration
+	// Strict makes any problem with the extensions fail the report, instead
+	// of only leaving out the extensions with problems.
+	Strict bool
+	// Diagnostics makes reports include the diagnostics of the extensions.
+	Diagnostics bool
+}
+
+func New(log logr.Logger, clusterID string, period time.Duration, db database.Database, extensions ExtensionsConfig, groupNodes bool, distributionRulesPath, cloudProviderRulesPath string) (*volunteer, error) {
 	kcw, err := newKubeClientWrapper()
 	if err != nil {
 		return nil, err
 	}
-	pel := pathExtensionsLister(extensionsPath)
-	return newVolunteer(log, clusterID, period, db, kcw, kcw, pel), nil
+	if cloudProviderRulesPath != "" {
+		var rules cloudProviderRules
+		if err := loadRules(cloudProviderRulesPath, "cloud provider", &rules); err != nil {
+			return nil, err
+		}
+		kcw.cloudProviderRules = rules
+	}
+	el := multiExtensionsLister{pathExtensionsLister(extensions.Path)}
+	if extensions.ConfigMapSelector != "" || extensions.AnnotationPrefix != "" {
+		if err := validLabelSelector(extensions.ConfigMapSelector); err != nil {
+			return nil, fmt.Errorf("invalid extensions ConfigMap selector: %v", err)
+		}
+		el = append(el, clusterExtensionsLister{
+			objects:           kcw,
+			configMapSelector: extensions.ConfigMapSelector,
+			annotationPrefix:  extensions.AnnotationPrefix,
+		})
+	}
+	if len(extensions.MetricsEndpoints) > 0 {
+		for _, endpoint := range extensions.MetricsEndpoints {
+			if err := validMetricsEndpoint(endpoint); err != nil {
+				return nil, fmt.Errorf("invalid extensions metrics endpoint %q: %v", endpoint, err)
+			}
+		}
+		ml := metricsExtensionsLister{
+			client:    &http.Client{Timeout: extensions.MetricsTimeout},
+			endpoints: extensions.MetricsEndpoints,
+			allowlist: extensions.MetricsAllowlist,
+		}
+		if extensions.MetricsInClusterAuth {
+			client, err := newMetricsClient(extensions.MetricsTimeout, serviceAccountCAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to set up extensions metrics auth: %v", err)
+			}
+			ml.client = client
+			ml.tokenFile = serviceAccountTokenFile
+		}
+		el = append(el, ml)
+	}
+	if extensions.HooksDir != "" {
+		el = append(el, hookExtensionsLister{
+			log:     log,
+			dir:     extensions.HooksDir,
+			timeout: extensions.HookTimeout,
+		})
+	}
+	v := newVolunteer(log, clusterID, period, db, listers{
+		nodeLister:               kcw,
+		serverVersioner:          kcw,
+		extensionsLister:         el,
+		namespaceLister:          kcw,
+		objectLister:             kcw,
+		apiGroupLister:           kcw,
+		systemWorkloadLister:     kcw,
+		storageLister:            kcw,
+		workloadFeatureLister:    kcw,
+		podLister:                kcw,
+		networkLister:            kcw,
+		distributionSignalLister: kcw,
+		listCache:                kcw,
+	})
+	v.groupNodes = groupNodes
+	v.strictExtensions = extensions.Strict
+	v.extensionDiagnostics = extensions.Diagnostics
+	if distributionRulesPath != "" {
+		var rules distributionRules
+		if err := loadRules(distributionRulesPath, "distribution", &rules); err != nil {
+			return nil, err
+		}
+		v.distributionRules = rules
+	}
+	return v, nil
 }
 
-func newVolunteer(
-	log logr.Logger,
-	clusterID string,
-	period time.Duration,
-	db database.Database,
-	nodeLister nodeLister,
-	serverVersioner serverVersioner,
-	extensionsLister extensionsLister) *volunteer {
+// listers are where a volunteer reads the cluster from.  In a cluster all
+// but the extensionsLister are a kubeClientWrapper, and tests replace them
+// with fakes.
+type listers struct {
+	nodeLister               nodeLister
+	serverVersioner          serverVersioner
+	extensionsLister         extensionsLister
+	namespaceLister          namespaceLister
+	objectLister             objectLister
+	apiGroupLister           apiGroupLister
+	systemWorkloadLister     systemWorkloadLister
+	storageLister            storageLister
+	workloadFeatureLister    workloadFeatureLister
+	podLister                podLister
+	networkLister            networkLister
+	distributionSignalLister distributionSignalLister
+	listCache                listCache
+}
 
+func newVolunteer(log logr.Logger, clusterID string, period time.Duration, db database.Database, l listers) *volunteer {
 	return &volunteer{
-		log:              log,
-		clusterID:        clusterID,
-		period:           period,
-		database:         db,
-		nodeLister:       nodeLister,
-		serverVersioner:  serverVersioner,
-		extensionsLister: extensionsLister,
+		log:               log,
+		clusterID:         clusterID,
+		period:            period,
+		database:          db,
+		listers:           l,
+		lifetimes:         newLifetimeTracker(l.objectLister),
+		distributionRules: defaultDistributionRules,
 	}
 }
 
 type volunteer struct {
-	clusterID        string
-	period           time.Duration
-	database         database.Database
-	log              logr.Logger
-	nodeLister       nodeLister
-	serverVersioner  serverVersioner
-	extensionsLister extensionsLister
+	clusterID string
+	period    time.Duration
+	database  database.Database
+	log       logr.Logger
+	listers
+	lifetimes *lifetimeTracker
+	// distributionRules are the rules for detecting the distribution.
+	distributionRules distributionRules
+	// groupNodes makes reports list node groups instead of nodes.
+	groupNodes bool
+	// strictExtensions makes any problem with the extensions fail the
+	// report, instead of only leaving out the extensions with problems.
+	strictExtensions bool
+	// extensionDiagnostics makes reports include the diagnostics of the
+	// extensions.
+	extensionDiagnostics bool
 }
 
 func (v *volunteer) Run() error {
@@ -102,6 +230,10 @@ func (v *volunteer) runOnce() error {
 }
 
 func (v *volunteer) generateRecord() (report.Record, error) {
+	// Each kind of object is listed once for the whole report, and listed
+	// again for the next one.
+	defer v.listCache.ClearListCache()
+
 	svrVer, err := v.serverVersioner.ServerVersion()
 	if err != nil {
 		return report.Record{}, err
@@ -112,19 +244,117 @@ func (v *volunteer) generateRecord() (report.Record, error) {
 		return report.Record{}, err
 	}
 
-	extensions, err := v.extensionsLister.ListExtensions()
-	if err != nil {
+	sources, err := v.extensionsLister.ListExtensions()
+	if err != nil && v.strictExtensions {
+		return report.Record{}, fmt.Errorf("failed to list extensions: %v", err)
+	} else if err != nil {
+		// Keep the extensions of the sources that could be listed.
 		v.log.Errorf("failed to list extensions: %v", err)
-		extensions = []report.Extension{}
+	}
+	extensions, extensionDiagnostics := flattenExtensions(sources)
+	for _, d := range extensionDiagnostics {
+		v.log.V(1).Infof("read %d extensions from %s", d.Count, d.Source)
+		for _, msg := range d.Errors {
+			v.log.Errorf("invalid extensions in %s: %s", d.Source, msg)
+		}
+		if len(d.Errors) > 0 && v.strictExtensions {
+			return report.Record{}, fmt.Errorf("invalid extensions in %s: %s", d.Source, strings.Join(d.Errors, "; "))
+		}
+	}
+
+	var namespaces *report.Namespaces
+	if counts, err := v.namespaceLister.ListNamespaces(); err != nil {
+		v.log.Errorf("failed to list namespaces: %v", err)
+	} else {
+		namespaces = namespacesFromCounts(counts)
+	}
+
+	lifetimes, err := v.lifetimes.Lifetimes()
+	if err != nil {
+		v.log.Errorf("failed to measure lifetimes: %v", err)
+	}
+
+	apiGroups, apiGroupsErr := v.apiGroupLister.ListAPIGroups()
+	if apiGroupsErr != nil {
+		v.log.Errorf("failed to list API groups: %v", apiGroupsErr)
+	}
+
+	// Detection needs both the API groups and the workloads, or projects
+	// would silently go missing.
+	var ecosystem *report.Ecosystem
+	if workloads, err := v.systemWorkloadLister.ListSystemWorkloads(); err != nil {
+		v.log.Errorf("failed to list system workloads: %v", err)
+	} else if apiGroupsErr == nil {
+		ecosystem = detectEcosystem(apiGroups, workloads)
+	}
+
+	var storage *report.Storage
+	if objs, err := v.storageLister.ListStorage(); err != nil {
+		v.log.Errorf("failed to list storage: %v", err)
+	} else {
+		storage = storageFromObjects(objs)
+	}
+
+	// The kinds that could not be counted are left out, but the others are
+	// still reported.
+	var workloadFeatures *report.WorkloadFeatures
+	counts, err := v.workloadFeatureLister.CountWorkloadFeatures()
+	if err != nil {
+		v.log.Errorf("failed to count workload features: %v", err)
+	}
+	if err == nil || len(counts) > 0 {
+		workloadFeatures = workloadFeaturesFromCounts(counts)
+	}
+
+	var podSecurity *report.PodSecurity
+	var images *report.Images
+	if pods, err := v.podLister.ListPods(); err != nil {
+		v.log.Errorf("failed to list pods: %v", err)
+	} else {
+		podSecurity = podSecurityFromList(pods.security)
+		images = imagesFromList(pods.images)
+	}
+
+	var networking *report.Networking
+	if objs, err := v.networkLister.ListNetwork(); err != nil {
+		v.log.Errorf("failed to list network objects: %v", err)
+	} else {
+		networking = networkingFromObjects(objs)
+	}
+
+	var distribution *report.KubernetesDistribution
+	if signals, err := v.distributionSignalLister.ListDistributionSignals(); err != nil {
+		v.log.Errorf("failed to list distribution signals: %v", err)
+	} else {
+		distribution = detectDistribution(v.distributionRules, svrVer, signals)
 	}
 
 	rec := report.Record{
-		Version:       version.VERSION,
-		Timestamp:     strconv.FormatInt(time.Now().Unix(), 10),
-		ClusterID:     v.clusterID,
-		MasterVersion: &svrVer,
-		Nodes:         nodes,
-		Extensions:    extensions,
+		Version:          version.VERSION,
+		Timestamp:        strconv.FormatInt(time.Now().Unix(), 10),
+		ClusterID:        v.clusterID,
+		MasterVersion:    &svrVer,
+		Nodes:            nodes,
+		Topology:         topologyFromNodes(nodes),
+		Summary:          summaryFromNodes(nodes),
+		Extensions:       extensions,
+		Namespaces:       namespaces,
+		Lifetimes:        lifetimes,
+		APIGroups:        apiGroups,
+		Ecosystem:        ecosystem,
+		Storage:          storage,
+		WorkloadFeatures: workloadFeatures,
+		PodSecurity:      podSecurity,
+		Networking:       networking,
+		Images:           images,
+		Distribution:     distribution,
+	}
+	if v.extensionDiagnostics {
+		rec.ExtensionDiagnostics = extensionDiagnostics
+	}
+	if v.groupNodes {
+		rec.Nodes = nil
+		rec.NodeGroups = groupNodes(nodes)
 	}
 
 	return rec, nil
@@ -134,15 +364,25 @@ func (v *volunteer) send(rec report.Record) error {
 	return v.database.Store(rec)
 }
 
+// extensionsSource is the extensions read from one source, such as a file,
+// along with the diagnostic of that source.
+type extensionsSource struct {
+	diagnostic report.ExtensionDiagnostic
+	extensions []report.Extension
+}
+
 type extensionsLister interface {
-	// ListExtensions returns a slice of report.Extensions, since that is the
+	// ListExtensions returns slices of report.Extensions, since that is the
 	// schema of the extensions in the database. Returning the array is nicer
 	// than returning a map since it means we don't have to encode any logic
 	// about transforming a map of extensions into an array of extensions in
 	// other parts of the the package. The format of the extensions file
 	// different from the database schema to be less verbose: writing
 	// {"k1": "v1", "k2": "v2"} is easier than [{"name": "k1", "value": "v1"}...
-	ListExtensions() ([]report.Extension, error)
+	// The extensions are grouped by the source they were read from, each
+	// with a diagnostic, so that users can find out why an extension was
+	// left out.  A lister that fails still returns the sources it read.
+	ListExtensions() ([]extensionsSource, error)
 }
 
 // pathExtensionsLister is higher level implementation of the extensionsLister
@@ -150,12 +390,12 @@ type extensionsLister interface {
 type pathExtensionsLister string
 
 // ListExtensions returns a slice of report.Extensions containing the
-// custom extensions that the user may want to report.
-func (p pathExtensionsLister) ListExtensions() ([]report.Extension, error) {
-	var extensions []report.Extension
-
+// custom extensions that the user may want to report.  Each file is a source
+// of its own.  Files that can not be parsed are left out, and so are keys
+// that another file already has.
+func (p pathExtensionsLister) ListExtensions() ([]extensionsSource, error) {
 	if p == "" {
-		return extensions, nil
+		return nil, nil
 	}
 
 	f, err := os.Stat(string(p))
@@ -180,43 +420,225 @@ func (p pathExtensionsLister) ListExtensions() ([]report.Extension, error) {
 		paths = append(paths, string(p))
 	}
 
+	// Files are read in the order of their names, so the first one wins.
+	merger := newExtensionsMerger()
 	for _, path := range paths {
+		diag := report.ExtensionDiagnostic{Source: filepath.Base(path)}
+		var es []report.Extension
 		extensionsBytes, err := ioutil.ReadFile(path)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read extensions file: %v", err)
-		}
-
-		es, err := byteExtensionsLister(extensionsBytes).ListExtensions()
-		if err == nil {
-			extensions = append(extensions, es...)
+			diag.Errors = append(diag.Errors, fmt.Sprintf("failed to read extensions file: %v", err))
+		} else if len(extensionsBytes) == 0 {
+			// An empty file has no extensions.
+		} else if all, errs, err := parseExtensions(extensionsBytes); err != nil {
+			diag.Errors = append(diag.Errors, err.Error())
+		} else {
+			es, diag = checkExtensions(diag.Source, all)
+			diag.Errors = append(errs, diag.Errors...)
 		}
+		merger.add(diag, es)
 	}
 
-	return extensions, nil
+	return merger.sources, nil
 }
 
 // byteExtensionsLister is a basic implementation of the extensionsLister
 // interface that reads extensions from a byte array.
 type byteExtensionsLister []byte
 
+// byteExtensionsSource is the source of the diagnostic of a
+// byteExtensionsLister, which does not know where its data came from.
+const byteExtensionsSource = "data"
+
 // ListExtensions returns a slice of report.Extensions containing the
 // custom extensions that the user may want to report.
-func (b byteExtensionsLister) ListExtensions() ([]report.Extension, error) {
-	var extensions []report.Extension
-
+func (b byteExtensionsLister) ListExtensions() ([]extensionsSource, error) {
 	if len(b) == 0 {
-		return extensions, nil
+		return nil, nil
 	}
 
-	extensionsMap := make(map[string]string)
-	err := json.Unmarshal(b, &extensionsMap)
+	all, errs, err := parseExtensions(b)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse extensions data: %v", err)
+		return nil, err
+	}
+	extensions, diag := checkExtensions(byteExtensionsSource, all)
+	diag.Errors = append(errs, diag.Errors...)
+
+	return []extensionsSource{{diagnostic: diag, extensions: extensions}}, nil
+}
+
+// multiExtensionsLister is an implementation of the extensionsLister
+// interface that combines the extensions of several listers.  Keys that an
+// earlier lister already has are left out.
+type multiExtensionsLister []extensionsLister
+
+// ListExtensions returns the sources of the custom extensions of all the
+// listers.  A lister that fails does not stop the others from being listed:
+// their sources, and those that the failing lister did read, are returned
+// along with the first error.
+func (m multiExtensionsLister) ListExtensions() ([]extensionsSource, error) {
+	merger := newExtensionsMerger()
+	var firstErr error
+	for _, l := range m {
+		sources, err := l.ListExtensions()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		for _, s := range sources {
+			merger.add(s.diagnostic, s.extensions)
+		}
 	}
+	return merger.sources, firstErr
+}
 
-	for k, v := range extensionsMap {
-		extensions = append(extensions, report.Extension{Name: k, Value: v})
+// flattenExtensions returns the extensions of all the sources, in order,
+// and the diagnostics of the sources.
+func flattenExtensions(sources []extensionsSource) ([]report.Extension, []report.ExtensionDiagnostic) {
+	extensions := []report.Extension{}
+	var diagnostics []report.ExtensionDiagnostic
+	for _, s := range sources {
+		extensions = append(extensions, s.extensions...)
+		diagnostics = append(diagnostics, s.diagnostic)
 	}
+	return extensions, diagnostics
+}
 
-	return extensions, nil
+// parseExtensions parses a JSON document of extensions.  Keys whose values
+// can not be extensions are left out, and an error message is returned for
+// each of them, so that one bad key does not lose the whole document.  It
+// fails only if the document can not be parsed at all.
+func parseExtensions(b []byte) ([]report.Extension, []string, error) {
+	// Numbers are decoded as json.Number, so that integers stay exact.
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	extensionsMap := make(map[string]interface{})
+	err := decoder.Decode(&extensionsMap)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse extensions data: %v", err)
+	}
+
+	extensions, errs := extensionsFromMap("", extensionsMap)
+	// We want to report the errors in a deterministic order.
+	sort.Strings(errs)
+	return extensions, errs, nil
 }
+
+// checkExtensions sorts the extensions of a source, and leaves out those
+// whose keys are duplicates or are not valid extension names, since the
+// collector would reject the whole report because of them.  It returns the
+// remaining extensions and the diagnostic of the source.
+func checkExtensions(source string, all []report.Extension) ([]report.Extension, report.ExtensionDiagnostic) {
+	extensions := []report.Extension{}
+	diag := report.ExtensionDiagnostic{Source: source}
+	// We want to report the extensions in a deterministic order.
+	sort.Sort(extensionsByName(all))
+	for i, e := range all {
+		// Flattening can make the same key twice, e.g. from {"a.b": 1} and
+		// {"a": {"b": 2}}.
+		if i > 0 && all[i-1].Name == e.Name {
+			diag.Errors = append(diag.Errors, fmt.Sprintf("%q: duplicate key", e.Name))
+			continue
+		}
+		if msgs := report.ValidateExtensionName(e.Name); len(msgs) > 0 {
+			diag.Errors = append(diag.Errors, fmt.Sprintf("%q: %s", e.Name, strings.Join(msgs, ", ")))
+			continue
+		}
+		extensions = append(extensions, e)
+		diag.Count++
+	}
+	return extensions, diag
+}
+
+// extensionsMerger combines the extensions of several sources.  Keys that
+// an earlier source already has are left out.
+type extensionsMerger struct {
+	sources []extensionsSource
+	// seen maps the keys seen so far to the source they were read from.
+	seen map[string]string
+}
+
+func newExtensionsMerger() *extensionsMerger {
+	return &extensionsMerger{seen: map[string]string{}}
+}
+
+// add adds the extensions of a source, and its diagnostic with the count of
+// the extensions that were kept.
+func (m *extensionsMerger) add(diag report.ExtensionDiagnostic, extensions []report.Extension) {
+	diag.Count = 0
+	var kept []report.Extension
+	for _, e := range extensions {
+		if source, found := m.seen[e.Name]; found {
+			diag.Errors = append(diag.Errors, fmt.Sprintf("%q: duplicate of a key in %s", e.Name, source))
+			continue
+		}
+		m.seen[e.Name] = diag.Source
+		kept = append(kept, e)
+		diag.Count++
+	}
+	m.sources = append(m.sources, extensionsSource{diagnostic: diag, extensions: kept})
+}
+
+// extensionsFromMap converts the values of a JSON object to extensions.  The
+// values of nested objects are flattened, with their keys joined by dots, so
+// that {"a": {"b": 1}} is reported as "a.b".  Values that can not be
+// converted are left out, with an error message for each.
+func extensionsFromMap(prefix string, m map[string]interface{}) ([]report.Extension, []string) {
+	var extensions []report.Extension
+	var errs []string
+	for k, v := range m {
+		name := k
+		if prefix != "" {
+			name = prefix + "." + k
+		}
+		if nested, ok := v.(map[string]interface{}); ok {
+			es, nestedErrs := extensionsFromMap(name, nested)
+			extensions = append(extensions, es...)
+			errs = append(errs, nestedErrs...)
+			continue
+		}
+		e, err := extensionFromValue(name, v)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		extensions = append(extensions, e)
+	}
+	return extensions, errs
+}
+
+// extensionFromValue converts a decoded JSON value to a typed extension.
+func extensionFromValue(name string, value interface{}) (report.Extension, error) {
+	e := report.Extension{Name: name}
+	switch v := value.(type) {
+	case string:
+		e.Value = v
+		e.Type = report.ExtensionTypeString
+	case bool:
+		e.Value = strconv.FormatBool(v)
+		e.Type = report.ExtensionTypeBool
+		e.BoolValue = &v
+	case json.Number:
+		e.Value = v.String()
+		if i, err := v.Int64(); err == nil {
+			e.Type = report.ExtensionTypeInt
+			e.IntValue = &i
+		} else if f, err := v.Float64(); err == nil {
+			e.Type = report.ExtensionTypeFloat
+			e.FloatValue = &f
+		} else {
+			return report.Extension{}, fmt.Errorf("%q: invalid number %s", name, v)
+		}
+	default:
+		// The value is shown as JSON, e.g. null rather than <nil>.
+		b, _ := json.Marshal(value)
+		return report.Extension{}, fmt.Errorf("%q: unsupported value %s, must be a string, number, bool or object", name, b)
+	}
+	return e, nil
+}
+
+// extensionsByName sorts extensions by name.
+type extensionsByName []report.Extension
+
+func (s extensionsByName) Len() int           { return len(s) }
+func (s extensionsByName) Less(i, j int) bool { return s[i].Name < s[j].Name }
+func (s extensionsByName) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
@@ -18,6 +18,10 @@ package volunteer
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -25,6 +29,7 @@ import (
 	"github.com/kubernetes-incubator/spartakus/pkg/database"
 	"github.com/kubernetes-incubator/spartakus/pkg/report"
 	"github.com/kubernetes-incubator/spartakus/pkg/version"
+	"github.com/kylelemons/godebug/pretty"
 	logrtest "github.com/thockin/logr/testing"
 )
 
@@ -42,13 +47,13 @@ func (fake fakeNodeLister) ListNodes() ([]report.Node, error) {
 
 // Fake out "list extension" calls.
 type fakeExtensionLister struct {
-	returnValue []report.Extension
+	returnValue []extensionsSource
 	returnError error
 }
 
 var _ extensionsLister = fakeExtensionLister{}
 
-func (fake fakeExtensionLister) ListExtensions() ([]report.Extension, error) {
+func (fake fakeExtensionLister) ListExtensions() ([]extensionsSource, error) {
 	return fake.returnValue, fake.returnError
 }
 
@@ -64,16 +69,140 @@ func (fake fakeServerVersioner) ServerVersion() (string, error) {
 	return fake.returnValue, fake.returnError
 }
 
+// Fake out "list namespaces" calls.
+type fakeNamespaceLister struct {
+	returnValue []namespaceCounts
+	returnError error
+}
+
+var _ namespaceLister = fakeNamespaceLister{}
+
+func (fake fakeNamespaceLister) ListNamespaces() ([]namespaceCounts, error) {
+	return fake.returnValue, fake.returnError
+}
+
+// Fake out "list objects" calls.
+type fakeObjectLister struct {
+	returnValue map[string][]objectTimes
+	returnError error
+}
+
+var _ objectLister = fakeObjectLister{}
+
+func (fake fakeObjectLister) ListObjects() (map[string][]objectTimes, error) {
+	return fake.returnValue, fake.returnError
+}
+
+// Fake out "list API groups" calls.
+type fakeAPIGroupLister struct {
+	returnValue []report.APIGroup
+	returnError error
+}
+
+var _ apiGroupLister = fakeAPIGroupLister{}
+
+func (fake fakeAPIGroupLister) ListAPIGroups() ([]report.APIGroup, error) {
+	return fake.returnValue, fake.returnError
+}
+
+// Fake out "list system workloads" calls.
+type fakeSystemWorkloadLister struct {
+	returnValue []string
+	returnError error
+}
+
+var _ systemWorkloadLister = fakeSystemWorkloadLister{}
+
+func (fake fakeSystemWorkloadLister) ListSystemWorkloads() ([]string, error) {
+	return fake.returnValue, fake.returnError
+}
+
+type fakeWorkloadFeatureLister struct {
+	returnValue map[string]int64
+	returnError error
+}
+
+var _ workloadFeatureLister = fakeWorkloadFeatureLister{}
+
+func (fake fakeWorkloadFeatureLister) CountWorkloadFeatures() (map[string]int64, error) {
+	return fake.returnValue, fake.returnError
+}
+
+type fakePodLister struct {
+	returnValue podObjects
+	returnError error
+}
+
+var _ podLister = fakePodLister{}
+
+func (fake fakePodLister) ListPods() (podObjects, error) {
+	return fake.returnValue, fake.returnError
+}
+
+type fakeNetworkLister struct {
+	returnValue networkObjects
+	returnError error
+}
+
+var _ networkLister = fakeNetworkLister{}
+
+func (fake fakeNetworkLister) ListNetwork() (networkObjects, error) {
+	return fake.returnValue, fake.returnError
+}
+
+type fakeDistributionSignalLister struct {
+	returnValue distributionSignals
+	returnError error
+}
+
+var _ distributionSignalLister = fakeDistributionSignalLister{}
+
+func (fake fakeDistributionSignalLister) ListDistributionSignals() (distributionSignals, error) {
+	return fake.returnValue, fake.returnError
+}
+
+type fakeListCache struct {
+	cleared int
+}
+
+var _ listCache = &fakeListCache{}
+
+func (fake *fakeListCache) ClearListCache() {
+	fake.cleared++
+}
+
+type fakeStorageLister struct {
+	returnValue storageObjects
+	returnError error
+}
+
+var _ storageLister = fakeStorageLister{}
+
+func (fake fakeStorageLister) ListStorage() (storageObjects, error) {
+	return fake.returnValue, fake.returnError
+}
+
 const fakeClusterID = "cluster"
 const fakePeriod = time.Hour
 
 func newTestVolunteer(t *testing.T) *volunteer {
 	log := &logrtest.TestLogger{T: t}
 	db := database.Database(nil)
-	nodes := &fakeNodeLister{}
-	vers := &fakeServerVersioner{}
-	exts := &fakeExtensionLister{}
-	return newVolunteer(log, fakeClusterID, fakePeriod, db, nodes, vers, exts)
+	return newVolunteer(log, fakeClusterID, fakePeriod, db, listers{
+		nodeLister:               &fakeNodeLister{},
+		serverVersioner:          &fakeServerVersioner{},
+		extensionsLister:         &fakeExtensionLister{},
+		namespaceLister:          &fakeNamespaceLister{},
+		objectLister:             &fakeObjectLister{},
+		apiGroupLister:           &fakeAPIGroupLister{},
+		systemWorkloadLister:     &fakeSystemWorkloadLister{},
+		storageLister:            &fakeStorageLister{},
+		workloadFeatureLister:    &fakeWorkloadFeatureLister{},
+		podLister:                &fakePodLister{},
+		networkLister:            &fakeNetworkLister{},
+		distributionSignalLister: &fakeDistributionSignalLister{},
+		listCache:                &fakeListCache{},
+	})
 }
 
 func TestGenerateRecord(t *testing.T) {
@@ -83,6 +212,18 @@ func TestGenerateRecord(t *testing.T) {
 		version    string
 		nodes      []string
 		extensions []string
+		namespaces int64
+		lifetimes  []string
+		apiGroups  []string
+		projects   []string
+		nodeGroups []int64
+		storage    int64  // number of storage classes, -1 for no storage
+		jobs       int64  // number of jobs if positive, -1 for no workload features
+		pods       int64  // number of pods, -1 for no pod security
+		services   int64  // number of services, -1 for no networking
+		containers int64  // number of containers, -1 for no images
+		distro     string // detected distribution if set, "-" for none
+		extDiags   int    // number of extension diagnostics
 	}{
 		{ // test serverVersioner failure
 			tweak: func(vol *volunteer) {
@@ -101,20 +242,167 @@ func TestGenerateRecord(t *testing.T) {
 				vol.extensionsLister.(*fakeExtensionLister).returnError = fmt.Errorf("fail")
 			},
 		},
+		{ // test extensionLister failure in strict mode
+			tweak: func(vol *volunteer) {
+				vol.strictExtensions = true
+				vol.extensionsLister.(*fakeExtensionLister).returnError = fmt.Errorf("fail")
+			},
+			errstr: "fail",
+		},
+		{ // test invalid extensions, should only be left out
+			tweak: func(vol *volunteer) {
+				vol.extensionsLister.(*fakeExtensionLister).returnValue = []extensionsSource{
+					{diagnostic: report.ExtensionDiagnostic{Source: "a.json", Errors: []string{`"Foo/bar": invalid`}}},
+				}
+			},
+		},
+		{ // test invalid extensions in strict mode
+			tweak: func(vol *volunteer) {
+				vol.strictExtensions = true
+				vol.extensionsLister.(*fakeExtensionLister).returnValue = []extensionsSource{
+					{diagnostic: report.ExtensionDiagnostic{Source: "a.json"}},
+					{diagnostic: report.ExtensionDiagnostic{Source: "b.json", Errors: []string{`"Foo/bar": invalid`}}},
+				}
+			},
+			errstr: "invalid extensions in b.json",
+		},
+		{ // test reporting extension diagnostics
+			tweak: func(vol *volunteer) {
+				vol.extensionDiagnostics = true
+				vol.extensionsLister.(*fakeExtensionLister).returnValue = []extensionsSource{
+					{diagnostic: report.ExtensionDiagnostic{Source: "a.json"}},
+					{diagnostic: report.ExtensionDiagnostic{Source: "b.json", Errors: []string{`"Foo/bar": invalid`}}},
+				}
+			},
+			extDiags: 2,
+		},
+		{ // test namespaceLister failure, should not cause total failure
+			tweak: func(vol *volunteer) {
+				vol.namespaceLister.(*fakeNamespaceLister).returnError = fmt.Errorf("fail")
+			},
+			namespaces: -1,
+		},
+		{ // test objectLister failure, should not cause total failure
+			tweak: func(vol *volunteer) {
+				vol.lifetimes.lister.(*fakeObjectLister).returnError = fmt.Errorf("fail")
+			},
+		},
+		{ // test apiGroupLister failure, should not cause total failure
+			tweak: func(vol *volunteer) {
+				vol.apiGroupLister.(*fakeAPIGroupLister).returnError = fmt.Errorf("fail")
+			},
+		},
+		{ // test systemWorkloadLister failure, should not cause total failure
+			tweak: func(vol *volunteer) {
+				vol.systemWorkloadLister.(*fakeSystemWorkloadLister).returnError = fmt.Errorf("fail")
+			},
+		},
+		{ // test storageLister failure, should not cause total failure
+			tweak: func(vol *volunteer) {
+				vol.storageLister.(*fakeStorageLister).returnError = fmt.Errorf("fail")
+			},
+			storage: -1,
+		},
+		{ // test workloadFeatureLister failure, should not cause total failure
+			tweak: func(vol *volunteer) {
+				vol.workloadFeatureLister.(*fakeWorkloadFeatureLister).returnError = fmt.Errorf("fail")
+			},
+			jobs: -1,
+		},
+		{ // test workloadFeatureLister partial failure, should keep the other counts
+			tweak: func(vol *volunteer) {
+				vol.workloadFeatureLister.(*fakeWorkloadFeatureLister).returnValue = map[string]int64{"jobs": 2}
+				vol.workloadFeatureLister.(*fakeWorkloadFeatureLister).returnError = fmt.Errorf("failed to list cronjobs: forbidden")
+			},
+			jobs: 2,
+		},
+		{ // test podLister failure, should not cause total failure
+			tweak: func(vol *volunteer) {
+				vol.podLister.(*fakePodLister).returnError = fmt.Errorf("fail")
+			},
+			pods:       -1,
+			containers: -1,
+		},
+		{ // test networkLister failure, should not cause total failure
+			tweak: func(vol *volunteer) {
+				vol.networkLister.(*fakeNetworkLister).returnError = fmt.Errorf("fail")
+			},
+			services: -1,
+		},
+		{ // test distributionSignalLister failure, should not cause total failure
+			tweak: func(vol *volunteer) {
+				vol.distributionSignalLister.(*fakeDistributionSignalLister).returnError = fmt.Errorf("fail")
+			},
+			distro: "-",
+		},
 		{ // test success
 			tweak: func(vol *volunteer) {
 				vol.serverVersioner.(*fakeServerVersioner).returnValue = "v1.2.3"
 				vol.nodeLister.(*fakeNodeLister).returnValue = []report.Node{
 					{ID: "node1"}, {ID: "node2"},
 				}
-				vol.extensionsLister.(*fakeExtensionLister).returnValue = []report.Extension{
-					{Name: "foo", Value: "bar"},
-					{Name: "foo", Value: "baz"},
+				vol.extensionsLister.(*fakeExtensionLister).returnValue = []extensionsSource{
+					{
+						diagnostic: report.ExtensionDiagnostic{Source: "data", Count: 2},
+						extensions: []report.Extension{
+							{Name: "foo", Value: "bar"},
+							{Name: "foo", Value: "baz"},
+						},
+					},
+				}
+				vol.namespaceLister.(*fakeNamespaceLister).returnValue = []namespaceCounts{
+					{"pods": 3}, {"pods": 0},
+				}
+				vol.lifetimes.lister.(*fakeObjectLister).returnValue = map[string][]objectTimes{
+					"pods":     {{uid: "1", created: time.Now()}},
+					"services": {},
+				}
+				vol.apiGroupLister.(*fakeAPIGroupLister).returnValue = []report.APIGroup{
+					{Name: "", Versions: []string{"v1"}},
+					{Name: "batch", Versions: []string{"v1", "v2alpha1"}},
+					{Name: "networking.istio.io", Versions: []string{"v1alpha3"}},
+				}
+				vol.systemWorkloadLister.(*fakeSystemWorkloadLister).returnValue = []string{"kube-dns"}
+				vol.workloadFeatureLister.(*fakeWorkloadFeatureLister).returnValue = map[string]int64{"jobs": 3}
+				vol.podLister.(*fakePodLister).returnValue = podObjects{
+					security: []podSecurity{{hostNetwork: true}, {runAsNonRoot: true}},
+					images:   []string{"nginx:1.11", "gcr.io/google_containers/pause-amd64:3.0"},
+				}
+				vol.networkLister.(*fakeNetworkLister).returnValue = networkObjects{
+					services: []service{{serviceType: "ClusterIP", clusterIPs: []string{"10.0.0.1"}}},
+				}
+				vol.distributionSignalLister.(*fakeDistributionSignalLister).returnValue = distributionSignals{
+					nodeLabels: []map[string]string{{"node.kubernetes.io/instance-type": "k3s"}},
+				}
+				vol.storageLister.(*fakeStorageLister).returnValue = storageObjects{
+					classes: []storageClass{{provisioner: "kubernetes.io/gce-pd", isDefault: true}},
 				}
 			},
 			version:    "v1.2.3",
 			nodes:      []string{"node1", "node2"},
 			extensions: []string{"bar", "baz"},
+			namespaces: 2,
+			lifetimes:  []string{"pods", "services"},
+			apiGroups:  []string{"", "batch", "networking.istio.io"},
+			projects:   []string{"istio", "kube-dns"},
+			storage:    1,
+			jobs:       3,
+			pods:       2,
+			services:   1,
+			containers: 2,
+			distro:     "k3s",
+		},
+		{ // test node grouping
+			tweak: func(vol *volunteer) {
+				vol.groupNodes = true
+				vol.nodeLister.(*fakeNodeLister).returnValue = []report.Node{
+					{ID: "node1", Architecture: strPtr("amd64")},
+					{ID: "node2", Architecture: strPtr("arm64")},
+					{ID: "node3", Architecture: strPtr("amd64")},
+				}
+			},
+			namespaces: 0,
+			nodeGroups: []int64{2, 1},
 		},
 	}
 
@@ -122,6 +410,9 @@ func TestGenerateRecord(t *testing.T) {
 		vol := newTestVolunteer(t)
 		tc.tweak(vol)
 		rec, err := vol.generateRecord()
+		if cleared := vol.listCache.(*fakeListCache).cleared; cleared != 1 {
+			t.Errorf("[%d] expected the list cache to be cleared once, got %d", i, cleared)
+		}
 		if err != nil && tc.errstr == "" {
 			t.Errorf("[%d] unexpected error %q", i, err)
 		} else if err != nil {
@@ -143,9 +434,90 @@ func TestGenerateRecord(t *testing.T) {
 			if len(rec.Nodes) != len(tc.nodes) {
 				t.Errorf("[%d] expected %d nodes, got %d", i, len(rec.Nodes), len(tc.nodes))
 			}
+			nodeCount := int64(len(tc.nodes))
+			for _, c := range tc.nodeGroups {
+				nodeCount += c
+			}
+			if rec.Summary == nil || rec.Summary.NodeCount != nodeCount {
+				t.Errorf("[%d] expected a summary of %d nodes, got %v", i, nodeCount, rec.Summary)
+			}
+			if len(rec.NodeGroups) != len(tc.nodeGroups) {
+				t.Errorf("[%d] expected %d node groups, got %d", i, len(tc.nodeGroups), len(rec.NodeGroups))
+			} else {
+				for j := range tc.nodeGroups {
+					if rec.NodeGroups[j].Count != tc.nodeGroups[j] {
+						t.Errorf("[%d] expected nodeGroup[%d].Count %d, got %d", i, j, tc.nodeGroups[j], rec.NodeGroups[j].Count)
+					}
+				}
+			}
+			if len(rec.ExtensionDiagnostics) != tc.extDiags {
+				t.Errorf("[%d] expected %d extension diagnostics, got %v", i, tc.extDiags, rec.ExtensionDiagnostics)
+			}
 			if len(rec.Extensions) != len(tc.extensions) {
 				t.Errorf("[%d] expected %d extensions, got %d", i, len(rec.Extensions), len(tc.extensions))
 			}
+			if tc.namespaces < 0 && rec.Namespaces != nil {
+				t.Errorf("[%d] expected no namespaces, got %v", i, rec.Namespaces)
+			} else if tc.namespaces >= 0 && (rec.Namespaces == nil || rec.Namespaces.Count != tc.namespaces) {
+				t.Errorf("[%d] expected %d namespaces, got %v", i, tc.namespaces, rec.Namespaces)
+			}
+			if tc.storage < 0 && rec.Storage != nil {
+				t.Errorf("[%d] expected no storage, got %v", i, rec.Storage)
+			} else if tc.storage >= 0 && (rec.Storage == nil || rec.Storage.ClassCount != tc.storage) {
+				t.Errorf("[%d] expected %d storage classes, got %v", i, tc.storage, rec.Storage)
+			}
+			if tc.jobs < 0 && rec.WorkloadFeatures != nil {
+				t.Errorf("[%d] expected no workload features, got %v", i, rec.WorkloadFeatures)
+			} else if tc.jobs >= 0 && rec.WorkloadFeatures == nil {
+				t.Errorf("[%d] expected workload features, got none", i)
+			} else if tc.jobs > 0 && (rec.WorkloadFeatures.Jobs == nil || *rec.WorkloadFeatures.Jobs != tc.jobs) {
+				t.Errorf("[%d] expected %d jobs, got %v", i, tc.jobs, rec.WorkloadFeatures)
+			}
+			if tc.pods < 0 && rec.PodSecurity != nil {
+				t.Errorf("[%d] expected no pod security, got %v", i, rec.PodSecurity)
+			} else if tc.pods >= 0 && (rec.PodSecurity == nil || rec.PodSecurity.PodCount != tc.pods) {
+				t.Errorf("[%d] expected %d pods, got %v", i, tc.pods, rec.PodSecurity)
+			}
+			if tc.services < 0 && rec.Networking != nil {
+				t.Errorf("[%d] expected no networking, got %v", i, rec.Networking)
+			} else if tc.services >= 0 && (rec.Networking == nil || rec.Networking.ServiceCount != tc.services) {
+				t.Errorf("[%d] expected %d services, got %v", i, tc.services, rec.Networking)
+			}
+			if tc.containers < 0 && rec.Images != nil {
+				t.Errorf("[%d] expected no images, got %v", i, rec.Images)
+			} else if tc.containers >= 0 && (rec.Images == nil || rec.Images.ContainerCount != tc.containers) {
+				t.Errorf("[%d] expected %d containers, got %v", i, tc.containers, rec.Images)
+			}
+			if tc.distro == "-" && rec.Distribution != nil {
+				t.Errorf("[%d] expected no distribution, got %v", i, rec.Distribution)
+			} else if tc.distro != "-" && rec.Distribution == nil {
+				t.Errorf("[%d] expected a distribution, got none", i)
+			} else if tc.distro != "" && tc.distro != "-" && rec.Distribution.Name != tc.distro {
+				t.Errorf("[%d] expected distribution %q, got %v", i, tc.distro, rec.Distribution)
+			}
+			if len(rec.Lifetimes) != len(tc.lifetimes) {
+				t.Errorf("[%d] expected %d lifetimes, got %d", i, len(tc.lifetimes), len(rec.Lifetimes))
+			}
+			for j := range rec.Lifetimes {
+				if rec.Lifetimes[j].Kind != tc.lifetimes[j] {
+					t.Errorf("[%d] expected lifetime[%d].Kind %q, got %q", i, j, tc.lifetimes[j], rec.Lifetimes[j].Kind)
+				}
+			}
+			if len(rec.APIGroups) != len(tc.apiGroups) {
+				t.Errorf("[%d] expected %d API groups, got %d", i, len(tc.apiGroups), len(rec.APIGroups))
+			}
+			for j := range rec.APIGroups {
+				if rec.APIGroups[j].Name != tc.apiGroups[j] {
+					t.Errorf("[%d] expected apiGroup[%d].Name %q, got %q", i, j, tc.apiGroups[j], rec.APIGroups[j].Name)
+				}
+			}
+			if tc.projects == nil {
+				if rec.Ecosystem != nil && len(rec.Ecosystem.Projects) != 0 {
+					t.Errorf("[%d] expected no projects, got %v", i, rec.Ecosystem.Projects)
+				}
+			} else if rec.Ecosystem == nil || !reflect.DeepEqual(rec.Ecosystem.Projects, tc.projects) {
+				t.Errorf("[%d] expected projects %v, got %v", i, tc.projects, rec.Ecosystem)
+			}
 			for j := range rec.Nodes {
 				if rec.Nodes[j].ID != tc.nodes[j] {
 					t.Errorf("[%d] expected node[%d].ID %q, got %q", i, j, rec.Nodes[j].ID, tc.nodes[j])
@@ -200,6 +572,7 @@ func TestExtensionsLister(t *testing.T) {
 				{
 					Name:  "foo",
 					Value: "bar",
+					Type:  "string",
 				},
 			},
 		},
@@ -211,18 +584,85 @@ func TestExtensionsLister(t *testing.T) {
 				{
 					Name:  "foo",
 					Value: "bar",
+					Type:  "string",
 				},
 				{
 					Name:  "baz",
 					Value: "qux",
+					Type:  "string",
+				},
+			},
+		},
+		{
+			lister: byteExtensionsLister([]byte(`{"replicas": 3, "ratio": 0.25, "big": 1e3, "enabled": false}`)),
+			length: 4,
+			err:    false,
+			extensions: []report.Extension{
+				{
+					Name:     "replicas",
+					Value:    "3",
+					Type:     "int",
+					IntValue: int64Ptr(3),
+				},
+				{
+					Name:       "ratio",
+					Value:      "0.25",
+					Type:       "float",
+					FloatValue: float64Ptr(0.25),
+				},
+				{
+					Name:       "big",
+					Value:      "1e3",
+					Type:       "float",
+					FloatValue: float64Ptr(1000),
+				},
+				{
+					Name:      "enabled",
+					Value:     "false",
+					Type:      "bool",
+					BoolValue: boolPtr(false),
+				},
+			},
+		},
+		{
+			lister: byteExtensionsLister([]byte(`{"example.com/db": {"engine": "postgres", "pool": {"size": 10}}}`)),
+			length: 2,
+			err:    false,
+			extensions: []report.Extension{
+				{
+					Name:  "example.com/db.engine",
+					Value: "postgres",
+					Type:  "string",
+				},
+				{
+					Name:     "example.com/db.pool.size",
+					Value:    "10",
+					Type:     "int",
+					IntValue: int64Ptr(10),
 				},
 			},
 		},
+		{
+			lister: byteExtensionsLister([]byte(`{"foo": ["bar"], "baz": "qux"}`)),
+			length: 1,
+			err:    false,
+		},
+		{
+			lister: byteExtensionsLister([]byte(`{"foo": null}`)),
+			length: 0,
+			err:    false,
+		},
+		{
+			lister: byteExtensionsLister([]byte(`["foo"]`)),
+			length: 0,
+			err:    true,
+		},
 	}
 
 	for i, tc := range testCases {
 		l := tc.lister
-		extensions, err := l.ListExtensions()
+		sources, err := l.ListExtensions()
+		extensions, _ := flattenExtensions(sources)
 
 		if tc.err {
 			if err == nil {
@@ -242,7 +682,7 @@ func TestExtensionsLister(t *testing.T) {
 			for _, tce := range tc.extensions {
 				var extensionsContaintsTestExtension bool
 				for _, e := range extensions {
-					if e == tce {
+					if reflect.DeepEqual(e, tce) {
 						extensionsContaintsTestExtension = true
 						break
 					}
@@ -254,3 +694,214 @@ func TestExtensionsLister(t *testing.T) {
 		}
 	}
 }
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
+
+func TestExtensionsListerDiagnostics(t *testing.T) {
+	testCases := []struct {
+		input       string
+		names       []string
+		diagnostics []report.ExtensionDiagnostic
+	}{
+		{
+			input: `{"example.com/foo": "bar", "baz": 1}`,
+			names: []string{"baz", "example.com/foo"},
+			diagnostics: []report.ExtensionDiagnostic{
+				{Source: "data", Count: 2},
+			},
+		},
+		{
+			input: `{"Example.com/foo": "bar", "example.com/": "x", "a/b/c": "y", "baz": 1}`,
+			names: []string{"baz"},
+			diagnostics: []report.ExtensionDiagnostic{
+				{Source: "data", Count: 1, Errors: []string{
+					`"Example.com/foo": prefix part must be a DNS sub-domain (e.g. 'example.com')`,
+					`"a/b/c": must consist of an optional DNS sub-domain prefix and a name, separated by a '/'`,
+					`"example.com/": name part must be non-empty`,
+				}},
+			},
+		},
+		{
+			input: `{"a.b": 1, "a": {"b": 2}}`,
+			names: []string{"a.b"},
+			diagnostics: []report.ExtensionDiagnostic{
+				{Source: "data", Count: 1, Errors: []string{`"a.b": duplicate key`}},
+			},
+		},
+		{
+			input: `{"foo": null, "bar": {"list": [1, 2], "ok": true}, "Baz/x": 1}`,
+			names: []string{"bar.ok"},
+			diagnostics: []report.ExtensionDiagnostic{
+				{Source: "data", Count: 1, Errors: []string{
+					`"bar.list": unsupported value [1,2], must be a string, number, bool or object`,
+					`"foo": unsupported value null, must be a string, number, bool or object`,
+					`"Baz/x": prefix part must be a DNS sub-domain (e.g. 'example.com')`,
+				}},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		sources, err := byteExtensionsLister([]byte(tc.input)).ListExtensions()
+		extensions, diagnostics := flattenExtensions(sources)
+		if err != nil {
+			t.Errorf("[%d] unexpected error %q", i, err)
+			continue
+		}
+		names := []string{}
+		for _, e := range extensions {
+			names = append(names, e.Name)
+		}
+		if !reflect.DeepEqual(names, tc.names) {
+			t.Errorf("[%d] expected extensions %v, got %v", i, tc.names, names)
+		}
+		if !reflect.DeepEqual(diagnostics, tc.diagnostics) {
+			t.Errorf("[%d] expected diagnostics %v, got %v", i, tc.diagnostics, diagnostics)
+		}
+	}
+}
+
+func TestPathExtensionsListerDiagnostics(t *testing.T) {
+	dir, err := ioutil.TempDir("", "extensions")
+	if err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"a.json":       `{"example.com/env": "prod", "example.com/tier": 1}`,
+		"b.json":       `{"example.com/env": "dev", "example.com/team": "infra"}`,
+		"c.json":       `not json`,
+		".hidden.json": `{"example.com/hidden": true}`,
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	sources, err := pathExtensionsLister(dir).ListExtensions()
+	extensions, diagnostics := flattenExtensions(sources)
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	names := []string{}
+	for _, e := range extensions {
+		names = append(names, e.Name)
+	}
+	expectNames := []string{"example.com/env", "example.com/tier", "example.com/team"}
+	if !reflect.DeepEqual(names, expectNames) {
+		t.Errorf("expected extensions %v, got %v", expectNames, names)
+	}
+	if extensions[0].Value != "prod" {
+		t.Errorf("expected the first file to win, got %v", extensions[0])
+	}
+	if len(diagnostics) != 3 {
+		t.Fatalf("expected 3 diagnostics, got %v", diagnostics)
+	}
+	expect := []report.ExtensionDiagnostic{
+		{Source: "a.json", Count: 2},
+		{Source: "b.json", Count: 1, Errors: []string{`"example.com/env": duplicate of a key in a.json`}},
+	}
+	if !reflect.DeepEqual(diagnostics[:2], expect) {
+		t.Errorf("expected diagnostics %v, got %v", expect, diagnostics[:2])
+	}
+	if d := diagnostics[2]; d.Source != "c.json" || d.Count != 0 || len(d.Errors) != 1 || !strings.Contains(d.Errors[0], "failed to parse") {
+		t.Errorf("expected a parse error for c.json, got %v", d)
+	}
+}
+
+func TestMultiExtensionsLister(t *testing.T) {
+	lister := multiExtensionsLister{
+		&fakeExtensionLister{
+			returnValue: []extensionsSource{
+				{
+					diagnostic: report.ExtensionDiagnostic{Source: "one.json", Count: 2},
+					extensions: []report.Extension{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}},
+				},
+				{
+					diagnostic: report.ExtensionDiagnostic{Source: "two.json", Count: 1, Errors: []string{"bad"}},
+					extensions: []report.Extension{{Name: "c", Value: "3"}},
+				},
+			},
+		},
+		&fakeExtensionLister{
+			returnValue: []extensionsSource{
+				{
+					diagnostic: report.ExtensionDiagnostic{Source: "configmap default/facts", Count: 2},
+					extensions: []report.Extension{{Name: "b", Value: "4"}, {Name: "d", Value: "5"}},
+				},
+			},
+		},
+	}
+
+	sources, err := lister.ListExtensions()
+	if err != nil {
+		t.Fatalf("unexpected error %q", err)
+	}
+	expect := []extensionsSource{
+		{
+			diagnostic: report.ExtensionDiagnostic{Source: "one.json", Count: 2},
+			extensions: []report.Extension{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}},
+		},
+		{
+			diagnostic: report.ExtensionDiagnostic{Source: "two.json", Count: 1, Errors: []string{"bad"}},
+			extensions: []report.Extension{{Name: "c", Value: "3"}},
+		},
+		{
+			diagnostic: report.ExtensionDiagnostic{Source: "configmap default/facts", Count: 1, Errors: []string{`"b": duplicate of a key in one.json`}},
+			extensions: []report.Extension{{Name: "d", Value: "5"}},
+		},
+	}
+	if !reflect.DeepEqual(sources, expect) {
+		t.Errorf("did not get expected sources:\n%s", pretty.Compare(sources, expect))
+	}
+
+	// A failing lister does not drop the extensions of the others, nor the
+	// sources that it did read.
+	failing := multiExtensionsLister{
+		&fakeExtensionLister{
+			returnValue: []extensionsSource{
+				{diagnostic: report.ExtensionDiagnostic{Source: "zero.json", Errors: []string{"failed to read"}}},
+			},
+			returnError: fmt.Errorf("fail"),
+		},
+		lister[0],
+	}
+	sources, err = failing.ListExtensions()
+	if err == nil {
+		t.Errorf("expected error, got %v", err)
+	}
+	extensions, diagnostics := flattenExtensions(sources)
+	if len(extensions) != 3 || len(diagnostics) != 3 || diagnostics[0].Source != "zero.json" {
+		t.Errorf("expected the extensions of the other listers, got %v and %v", extensions, diagnostics)
+	}
+}
+
+func TestFlattenExtensions(t *testing.T) {
+	extensions, diagnostics := flattenExtensions(nil)
+	if extensions == nil || len(extensions) != 0 || diagnostics != nil {
+		t.Errorf("expected no extensions and no diagnostics, got %v and %v", extensions, diagnostics)
+	}
+
+	extensions, diagnostics = flattenExtensions([]extensionsSource{
+		{
+			diagnostic: report.ExtensionDiagnostic{Source: "one.json", Count: 1},
+			extensions: []report.Extension{{Name: "a", Value: "1"}},
+		},
+		{diagnostic: report.ExtensionDiagnostic{Source: "two.json", Errors: []string{"bad"}}},
+	})
+	expect := []report.Extension{{Name: "a", Value: "1"}}
+	if !reflect.DeepEqual(extensions, expect) {
+		t.Errorf("expected extensions %v, got %v", expect, extensions)
+	}
+	expectDiags := []report.ExtensionDiagnostic{
+		{Source: "one.json", Count: 1},
+		{Source: "two.json", Errors: []string{"bad"}},
+	}
+	if !reflect.DeepEqual(diagnostics, expectDiags) {
+		t.Errorf("expected diagnostics %v, got %v", expectDiags, diagnostics)
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+)
+
+type workloadFeatureLister interface {
+	// CountWorkloadFeatures returns the number of objects of each of the
+	// featureKinds across all namespaces.  Kinds that the cluster does not
+	// serve are left out.  Kinds that can not be counted, e.g. for lack of
+	// permission, are left out too, and the error says which they are.
+	CountWorkloadFeatures() (map[string]int64, error)
+}
+
+// featureKinds are the kinds of objects whose use is counted.  The API paths
+// that list them are in kindGroupVersions.  A kind that the cluster does not
+// serve is counted by the names that older versions of kubernetes served it
+// by, if any.
+var featureKinds = []struct {
+	kind     string
+	oldNames []string
+}{
+	{"cronjobs", []string{"scheduledjobs"}},
+	{"daemonsets", nil},
+	{"horizontalpodautoscalers", nil},
+	{"ingresses", nil},
+	{"jobs", nil},
+	{"limitranges", nil},
+	{"networkpolicies", nil},
+	{"poddisruptionbudgets", nil},
+	{"resourcequotas", nil},
+	{"statefulsets", []string{"petsets"}},
+}
+
+// workloadFeaturesFromCounts builds the report section for the object counts.
+func workloadFeaturesFromCounts(counts map[string]int64) *report.WorkloadFeatures {
+	count := func(kind string) *int64 {
+		n, found := counts[kind]
+		if !found {
+			return nil
+		}
+		return &n
+	}
+	return &report.WorkloadFeatures{
+		CronJobs:                 count("cronjobs"),
+		DaemonSets:               count("daemonsets"),
+		HorizontalPodAutoscalers: count("horizontalpodautoscalers"),
+		Ingresses:                count("ingresses"),
+		Jobs:                     count("jobs"),
+		LimitRanges:              count("limitranges"),
+		NetworkPolicies:          count("networkpolicies"),
+		PodDisruptionBudgets:     count("poddisruptionbudgets"),
+		ResourceQuotas:           count("resourcequotas"),
+		StatefulSets:             count("statefulsets"),
+	}
+}
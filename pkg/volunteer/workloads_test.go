@@ -0,0 +1,66 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package volunteer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kubernetes-incubator/spartakus/pkg/report"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestWorkloadFeaturesFromCounts(t *testing.T) {
+	counts := map[string]int64{
+		"cronjobs":                 0,
+		"horizontalpodautoscalers": 4,
+		"statefulsets":             2,
+		"unknown":                  7,
+	}
+	expect := &report.WorkloadFeatures{
+		CronJobs:                 int64Ptr(0),
+		HorizontalPodAutoscalers: int64Ptr(4),
+		StatefulSets:             int64Ptr(2),
+	}
+
+	wf := workloadFeaturesFromCounts(counts)
+	if !reflect.DeepEqual(wf, expect) {
+		t.Errorf("did not get expected result:\n%s", pretty.Compare(wf, expect))
+	}
+}
+
+func TestFeatureKinds(t *testing.T) {
+	// Every kind must have a field in the report.
+	counts := map[string]int64{}
+	for _, fk := range featureKinds {
+		for _, kind := range append([]string{fk.kind}, fk.oldNames...) {
+			if len(listPaths(kind, "")) == 0 {
+				t.Errorf("kind %q has no paths", kind)
+			}
+		}
+		counts[fk.kind] = 1
+	}
+	wf := reflect.ValueOf(*workloadFeaturesFromCounts(counts))
+	for i := 0; i < wf.NumField(); i++ {
+		if wf.Field(i).IsNil() {
+			t.Errorf("field %s is not set from any kind", wf.Type().Field(i).Name)
+		}
+	}
+	if wf.NumField() != len(featureKinds) {
+		t.Errorf("expected %d fields, got %d", len(featureKinds), wf.NumField())
+	}
+}